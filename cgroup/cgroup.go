@@ -0,0 +1,172 @@
+package cgroup
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Bo0mer/yamt/internal"
+	"github.com/Bo0mer/yamt/internal/hostfs"
+	"github.com/Bo0mer/yamt/psi"
+)
+
+//go:generate counterfeiter . CgroupStatReader
+
+// CgroupStat represents resource usage of a single cgroup. Values which are
+// not available for the cgroup, e.g. because the controller is not enabled,
+// are left zero and the corresponding Has* field is false.
+type CgroupStat struct {
+	// Path of the cgroup relative to the hierarchy root, "/" for the root
+	// itself.
+	Path string
+
+	HasCPU bool
+	// Total CPU time consumed, in microseconds.
+	CPUUsageUsec uint64
+	// CPU time consumed in user mode, in microseconds.
+	CPUUserUsec uint64
+	// CPU time consumed in kernel mode, in microseconds.
+	CPUSystemUsec uint64
+	// Number of elapsed enforcement periods.
+	NrPeriods uint64
+	// Number of periods in which the cgroup was throttled.
+	NrThrottled uint64
+	// Total time the cgroup was throttled for, in microseconds.
+	ThrottledUsec uint64
+
+	HasMemory bool
+	// Total memory currently used, in bytes.
+	MemoryCurrent uint64
+	// Anonymous memory, in bytes.
+	MemoryAnon uint64
+	// Page cache memory, in bytes.
+	MemoryFile uint64
+	// Shared memory, in bytes.
+	MemoryShmem uint64
+	// Page cache waiting to be written back, in bytes.
+	MemoryDirty uint64
+	// Page cache being written back, in bytes.
+	MemoryWriteback uint64
+	// Number of page faults.
+	PgFault uint64
+	// Number of major page faults.
+	PgMajFault uint64
+
+	HasIO bool
+	// Bytes read, summed across all devices.
+	IOReadBytes uint64
+	// Bytes written, summed across all devices.
+	IOWriteBytes uint64
+	// Read operations, summed across all devices.
+	IOReads uint64
+	// Write operations, summed across all devices.
+	IOWrites uint64
+
+	HasPids bool
+	// Number of processes in the cgroup.
+	PidsCurrent uint64
+
+	// Pressure stall information, if reported for the cgroup.
+	Pressure []psi.PressureStat
+}
+
+// CgroupStatReader should read statistics for all monitored cgroups.
+type CgroupStatReader interface {
+	ReadStats() ([]CgroupStat, error)
+}
+
+// IsUnified reports whether a cgroup v2 (unified) hierarchy is mounted at
+// root. Hosts where it is not should be monitored using V1Reader. Paths
+// under /sys are resolved relative to hostfs.SysRoot.
+func IsUnified(root string) bool {
+	_, err := os.Stat(filepath.Join(hostfs.Resolve(root), "cgroup.controllers"))
+	return err == nil
+}
+
+// parseKeyValues parses flat keyed files, i.e. lines in "key value" format,
+// such as cpu.stat and memory.stat.
+func parseKeyValues(data []byte) (map[string]uint64, error) {
+	values := make(map[string]uint64)
+	p := &internal.ErrParser{}
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		values[fields[0]] = p.ParseUint64(fields[1])
+	}
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// parseSingleValue parses files containing a single value, such as
+// memory.current.
+func parseSingleValue(data []byte) (uint64, error) {
+	p := &internal.ErrParser{}
+	v := p.ParseUint64(strings.TrimSpace(string(data)))
+	return v, p.Err()
+}
+
+// walk calls fn for each cgroup in the hierarchy mounted at root whose path
+// matches match, not descending deeper than maxDepth. Cgroups removed during
+// the walk are skipped.
+func walk(root string, match *regexp.Regexp, maxDepth int, fn func(dir, cgroup string) error) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+
+		cgroup, depth := relativePath(root, path)
+		if maxDepth >= 0 && depth > maxDepth {
+			return filepath.SkipDir
+		}
+		if match != nil && !match.MatchString(cgroup) {
+			return nil
+		}
+
+		if err := fn(path, cgroup); err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return fmt.Errorf("error reading cgroup %s: %v", cgroup, err)
+		}
+		return nil
+	})
+}
+
+// relativePath returns cgroup path of dir relative to root and its depth.
+func relativePath(root, dir string) (string, int) {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == "." {
+		return "/", 0
+	}
+	rel = filepath.ToSlash(rel)
+	return "/" + rel, strings.Count(rel, "/") + 1
+}
+
+// readOptional reads the named file in dir. Missing files are reported by
+// ok being false rather than by an error.
+func readOptional(dir, name string) (data []byte, ok bool, err error) {
+	data, err = ioutil.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	return data, true, nil
+}
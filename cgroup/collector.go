@@ -0,0 +1,156 @@
+package cgroup
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Bo0mer/yamt/internal"
+	"github.com/Bo0mer/yamt/metric"
+	"github.com/Bo0mer/yamt/psi"
+)
+
+type state map[string]CgroupStat
+
+// CgroupCollector computes metrics for cgroups. It works with both cgroup v1
+// and v2 readers and reports the same metrics for both.
+type CgroupCollector struct {
+	reader   CgroupStatReader
+	last     state
+	lastTime time.Time
+}
+
+// NewCgroupCollector returns brand new cgroup collector.
+func NewCgroupCollector(reader CgroupStatReader) (*CgroupCollector, error) {
+	c := &CgroupCollector{
+		reader: reader,
+	}
+	if err := c.init(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Collect collects stats and creates events for cgroups.
+func (c *CgroupCollector) Collect() ([]metric.Event, error) {
+	actual, err := c.getState()
+	if err != nil {
+		return nil, err
+	}
+
+	actualTime := time.Now()
+	interval := actualTime.Sub(c.lastTime).Seconds()
+
+	events := make([]metric.Event, 0)
+
+	for _, stat := range actual {
+		last, ok := c.last[stat.Path]
+		if !ok {
+			continue
+		}
+
+		events = append(events, c.buildEvents(stat, last, interval)...)
+	}
+
+	c.last = actual
+	c.lastTime = actualTime
+
+	return events, nil
+}
+
+// init loads the initial state of the collector.
+func (c *CgroupCollector) init() error {
+	state, err := c.getState()
+	if err != nil {
+		return err
+	}
+	c.last = state
+	c.lastTime = time.Now()
+	return nil
+}
+
+// getState reads current state for all cgroups.
+func (c *CgroupCollector) getState() (state, error) {
+	state := make(map[string]CgroupStat)
+	stats, err := c.reader.ReadStats()
+	if err != nil {
+		return nil, fmt.Errorf("collector: error reading stats: %v", err)
+	}
+	for _, stat := range stats {
+		state[stat.Path] = stat
+	}
+	return state, nil
+}
+
+// buildEvents builds all events for a single cgroup.
+func (c *CgroupCollector) buildEvents(actual, last CgroupStat, interval float64) []metric.Event {
+	events := make([]metric.Event, 0)
+	event := eventBuilder(actual.Path)
+	rate := internal.RateComputer(interval)
+	// percent converts rate of microseconds per second to percents.
+	percent := func(actual, last uint64) float64 {
+		return rate(actual, last) / 1e4
+	}
+
+	if actual.HasCPU {
+		events = append(events, event("cpu usage(%)", percent(actual.CPUUsageUsec, last.CPUUsageUsec)))
+		events = append(events, event("cpu user(%)", percent(actual.CPUUserUsec, last.CPUUserUsec)))
+		events = append(events, event("cpu system(%)", percent(actual.CPUSystemUsec, last.CPUSystemUsec)))
+		events = append(events, event("cpu periods", rate(actual.NrPeriods, last.NrPeriods)))
+		events = append(events, event("cpu throttled periods", rate(actual.NrThrottled, last.NrThrottled)))
+		events = append(events, event("cpu throttled(us)", rate(actual.ThrottledUsec, last.ThrottledUsec)))
+	}
+
+	if actual.HasMemory {
+		events = append(events, event("memory current(bytes)", float64(actual.MemoryCurrent)))
+		events = append(events, event("memory anon(bytes)", float64(actual.MemoryAnon)))
+		events = append(events, event("memory file(bytes)", float64(actual.MemoryFile)))
+		events = append(events, event("memory shmem(bytes)", float64(actual.MemoryShmem)))
+		events = append(events, event("memory dirty(bytes)", float64(actual.MemoryDirty)))
+		events = append(events, event("memory writeback(bytes)", float64(actual.MemoryWriteback)))
+		events = append(events, event("memory pgfault", rate(actual.PgFault, last.PgFault)))
+		events = append(events, event("memory pgmajfault", rate(actual.PgMajFault, last.PgMajFault)))
+	}
+
+	if actual.HasIO {
+		events = append(events, event("io read bytes", rate(actual.IOReadBytes, last.IOReadBytes)))
+		events = append(events, event("io write bytes", rate(actual.IOWriteBytes, last.IOWriteBytes)))
+		events = append(events, event("io reads", rate(actual.IOReads, last.IOReads)))
+		events = append(events, event("io writes", rate(actual.IOWrites, last.IOWrites)))
+	}
+
+	if actual.HasPids {
+		events = append(events, event("pids current", float64(actual.PidsCurrent)))
+	}
+
+	for _, pressure := range actual.Pressure {
+		lastPressure, ok := findPressure(last.Pressure, pressure.Resource)
+		if !ok {
+			continue
+		}
+		for _, e := range psi.BuildEvents("cgroup "+actual.Path+" psi", pressure, lastPressure, interval) {
+			e.Attributes = map[string]string{"cgroup": actual.Path}
+			events = append(events, e)
+		}
+	}
+
+	return events
+}
+
+func findPressure(stats []psi.PressureStat, resource string) (psi.PressureStat, bool) {
+	for _, stat := range stats {
+		if stat.Resource == resource {
+			return stat, true
+		}
+	}
+	return psi.PressureStat{}, false
+}
+
+func eventBuilder(cgroup string) func(string, float64) metric.Event {
+	return func(name string, value float64) metric.Event {
+		return metric.Event{
+			Name:       "cgroup " + cgroup + " " + name,
+			Value:      value,
+			Attributes: map[string]string{"cgroup": cgroup},
+		}
+	}
+}
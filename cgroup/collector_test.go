@@ -0,0 +1,111 @@
+package cgroup_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Bo0mer/yamt/cgroup"
+	"github.com/Bo0mer/yamt/cgroup/cgroupfakes"
+	"github.com/Bo0mer/yamt/metric"
+)
+
+// Test that *CgroupCollector implements metric.Collector
+var _ metric.Collector = (*cgroup.CgroupCollector)(nil)
+
+func TestNewCgroupCollector(t *testing.T) {
+	errReader := new(cgroupfakes.FakeCgroupStatReader)
+	errReader.ReadStatsReturns(nil, errors.New("kaboom"))
+	_, err := cgroup.NewCgroupCollector(errReader)
+	if err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+var cgroupPath = "/system.slice/nginx.service"
+
+var stats = map[int][]cgroup.CgroupStat{
+	0: []cgroup.CgroupStat{
+		cgroup.CgroupStat{
+			Path:          cgroupPath,
+			HasMemory:     true,
+			MemoryCurrent: 1024,
+			HasPids:       true,
+			PidsCurrent:   2,
+		},
+		cgroup.CgroupStat{
+			Path:        "/gone.service",
+			HasPids:     true,
+			PidsCurrent: 1,
+		},
+	},
+	1: []cgroup.CgroupStat{
+		cgroup.CgroupStat{
+			Path:          cgroupPath,
+			HasMemory:     true,
+			MemoryCurrent: 2048,
+			PgFault:       100,
+			HasPids:       true,
+			PidsCurrent:   3,
+		},
+		cgroup.CgroupStat{
+			Path:        "/new.service",
+			HasPids:     true,
+			PidsCurrent: 1,
+		},
+	},
+}
+
+func newFakedReader(t *testing.T) cgroup.CgroupStatReader {
+	r := new(cgroupfakes.FakeCgroupStatReader)
+	i := 0
+	r.ReadStatsStub = func() ([]cgroup.CgroupStat, error) {
+		ret := stats[i]
+		i++
+		return ret, nil
+	}
+	return r
+}
+
+func TestCgroupCollectorCollect(t *testing.T) {
+	attributes := map[string]string{"cgroup": cgroupPath}
+	want := []metric.Event{
+		metric.Event{Name: "cgroup /system.slice/nginx.service memory current(bytes)", Value: 2048.0, Attributes: attributes},
+		metric.Event{Name: "cgroup /system.slice/nginx.service memory anon(bytes)", Value: 0.0, Attributes: attributes},
+		metric.Event{Name: "cgroup /system.slice/nginx.service memory file(bytes)", Value: 0.0, Attributes: attributes},
+		metric.Event{Name: "cgroup /system.slice/nginx.service memory shmem(bytes)", Value: 0.0, Attributes: attributes},
+		metric.Event{Name: "cgroup /system.slice/nginx.service memory dirty(bytes)", Value: 0.0, Attributes: attributes},
+		metric.Event{Name: "cgroup /system.slice/nginx.service memory writeback(bytes)", Value: 0.0, Attributes: attributes},
+		metric.Event{}, // memory pgfault, handled separately
+		metric.Event{Name: "cgroup /system.slice/nginx.service memory pgmajfault", Value: 0.0, Attributes: attributes},
+		metric.Event{Name: "cgroup /system.slice/nginx.service pids current", Value: 3.0, Attributes: attributes},
+	}
+
+	c, err := cgroup.NewCgroupCollector(newFakedReader(t))
+	if err != nil {
+		t.Errorf("unexpected error: %v\n", err)
+	}
+	got, err := c.Collect()
+	if err != nil {
+		t.Errorf("unexpected error: %v\n", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d events, got %d\n", len(want), len(got))
+	}
+
+	for i := range got {
+		if got[i].Name == "cgroup /system.slice/nginx.service memory pgfault" {
+			if f, ok := got[i].Value.(float64); !ok {
+				t.Errorf("expected float64 value, got %T\n", got[i].Value)
+			} else {
+				if f <= 0 {
+					t.Errorf("expected positive value, got %f\n", f)
+				}
+			}
+			continue
+		}
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("expected %#v, got %#v\n", want[i], got[i])
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package cgroup
+
+import (
+	"log"
+
+	"github.com/Bo0mer/yamt/internal/hostfs"
+	"github.com/Bo0mer/yamt/metric"
+	"github.com/Bo0mer/yamt/registry"
+)
+
+func init() {
+	registry.Register(registry.Collector{
+		Name:        "cgroup",
+		Description: "Report cgroup metrics",
+		Options: []registry.Option{
+			{Name: "cgroup-root", Usage: "Cgroup v2 hierarchy mount point, cgroup v1 hierarchies are used if missing", Default: "/sys/fs/cgroup"},
+			{Name: "cgroup-match", Usage: "Cgroup paths to report"},
+			{Name: "cgroup-depth", Kind: registry.Int, Usage: "Maximum depth of reported cgroups, negative for unlimited", Default: "2"},
+		},
+		New: newCgroupCollector,
+	})
+}
+
+func newCgroupCollector(c registry.Config) ([]metric.Collector, error) {
+	match, err := c.Regexp("cgroup-match")
+	if err != nil {
+		return nil, err
+	}
+	depth, err := c.Int("cgroup-depth")
+	if err != nil {
+		return nil, err
+	}
+	root := c.String("cgroup-root")
+	var reader CgroupStatReader = NewV2Reader(root, match, depth)
+	if !IsUnified(root) {
+		reader = NewV1Reader(hostfs.MountsFile("mountinfo"), match, depth)
+		log.Printf("cgroup: no unified cgroup hierarchy at %s, falling back to cgroup v1", root)
+	}
+	cc, err := NewCgroupCollector(reader)
+	if err != nil {
+		return nil, err
+	}
+	return []metric.Collector{metric.Named("cgroup", cc)}, nil
+}
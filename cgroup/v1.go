@@ -0,0 +1,267 @@
+package cgroup
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Bo0mer/yamt/internal"
+	"github.com/Bo0mer/yamt/internal/hostfs"
+)
+
+// userHZ is the number of clock ticks per second used by cpuacct.stat.
+const userHZ = 100
+
+// V1Reader reads statistics from cgroup v1 hierarchies. Controller mount
+// points are discovered from mountinfo on each read.
+type V1Reader struct {
+	mountinfo string
+	match     *regexp.Regexp
+	maxDepth  int
+}
+
+// NewV1Reader creates V1Reader that discovers controller hierarchies from
+// the specified mountinfo file. See NewV2Reader for meaning of match and
+// maxDepth. The mountinfo path and the discovered mount points are host paths,
+// resolved using hostfs.Resolve. When hostfs.ProcRoot is not /proc, the
+// mountinfo of the host init process, e.g. /proc/1/mountinfo, should be used
+// as /proc/self refers to the mount namespace of the reader.
+func NewV1Reader(mountinfo string, match *regexp.Regexp, maxDepth int) *V1Reader {
+	return &V1Reader{
+		mountinfo: mountinfo,
+		match:     match,
+		maxDepth:  maxDepth,
+	}
+}
+
+// DefaultV1Reader is the default implementation of CgroupStatReader for
+// cgroup v1. It discovers hierarchies from /proc/self/mountinfo and reads
+// their roots and direct children.
+var DefaultV1Reader CgroupStatReader = NewV1Reader("/proc/self/mountinfo", nil, 1)
+
+// ReadStats reads statistics for all matching cgroups from the cpu, cpuacct,
+// memory, blkio and pids controllers. Values are converted to the units used
+// by cgroup v2, so the same metrics are reported for both.
+func (r *V1Reader) ReadStats() ([]CgroupStat, error) {
+	mounts, err := r.readMounts()
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make(map[string]*CgroupStat)
+	readers := []struct {
+		controller string
+		read       func(stat *CgroupStat, dir string) error
+	}{
+		{"cpuacct", r.readCPUAcct},
+		{"cpu", r.readCPU},
+		{"memory", r.readMemory},
+		{"blkio", r.readBlkio},
+		{"pids", r.readPids},
+	}
+	for _, reader := range readers {
+		mount, ok := mounts[reader.controller]
+		if !ok {
+			continue
+		}
+		mount = hostfs.Resolve(mount)
+		read := reader.read
+		err := walk(mount, r.match, r.maxDepth, func(dir, cgroup string) error {
+			stat, ok := stats[cgroup]
+			if !ok {
+				stat = &CgroupStat{Path: cgroup}
+			}
+			if err := read(stat, dir); err != nil {
+				return err
+			}
+			stats[cgroup] = stat
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("readcgroupv1: error reading from %s: %v", mount, err)
+		}
+	}
+
+	paths := make([]string, 0, len(stats))
+	for path := range stats {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	result := make([]CgroupStat, 0, len(paths))
+	for _, path := range paths {
+		result = append(result, *stats[path])
+	}
+	return result, nil
+}
+
+// readMounts returns mount points of cgroup v1 controllers.
+func (r *V1Reader) readMounts() (map[string]string, error) {
+	path := hostfs.Resolve(r.mountinfo)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("readcgroupv1: error reading from %s: %v", path, err)
+	}
+	return parseMountinfo(data)
+}
+
+// parseMountinfo returns mount points of cgroup v1 controllers, keyed by
+// controller name.
+func parseMountinfo(data []byte) (map[string]string, error) {
+	mounts := make(map[string]string)
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		line := s.Text()
+		sep := strings.Index(line, " - ")
+		if sep < 0 {
+			continue
+		}
+		fields, super := strings.Fields(line[:sep]), strings.Fields(line[sep+3:])
+		if len(fields) < 5 || len(super) < 3 {
+			return nil, fmt.Errorf("readcgroupv1: unsupported mountinfo format: %q", line)
+		}
+		if super[0] != "cgroup" {
+			continue
+		}
+		for _, opt := range strings.Split(super[2], ",") {
+			if _, ok := mounts[opt]; !ok {
+				mounts[opt] = fields[4]
+			}
+		}
+	}
+	return mounts, nil
+}
+
+func (r *V1Reader) readCPUAcct(stat *CgroupStat, dir string) error {
+	if data, ok, err := readOptional(dir, "cpuacct.usage"); err != nil {
+		return err
+	} else if ok {
+		usage, err := parseSingleValue(data)
+		if err != nil {
+			return err
+		}
+		stat.HasCPU = true
+		stat.CPUUsageUsec = usage / 1000
+	}
+
+	if data, ok, err := readOptional(dir, "cpuacct.stat"); err != nil {
+		return err
+	} else if ok {
+		values, err := parseKeyValues(data)
+		if err != nil {
+			return err
+		}
+		stat.CPUUserUsec = values["user"] * 1e6 / userHZ
+		stat.CPUSystemUsec = values["system"] * 1e6 / userHZ
+	}
+	return nil
+}
+
+func (r *V1Reader) readCPU(stat *CgroupStat, dir string) error {
+	data, ok, err := readOptional(dir, "cpu.stat")
+	if err != nil || !ok {
+		return err
+	}
+	values, err := parseKeyValues(data)
+	if err != nil {
+		return err
+	}
+	stat.HasCPU = true
+	stat.NrPeriods = values["nr_periods"]
+	stat.NrThrottled = values["nr_throttled"]
+	stat.ThrottledUsec = values["throttled_time"] / 1000
+	return nil
+}
+
+func (r *V1Reader) readMemory(stat *CgroupStat, dir string) error {
+	if data, ok, err := readOptional(dir, "memory.usage_in_bytes"); err != nil {
+		return err
+	} else if ok {
+		usage, err := parseSingleValue(data)
+		if err != nil {
+			return err
+		}
+		stat.HasMemory = true
+		stat.MemoryCurrent = usage
+	}
+
+	if data, ok, err := readOptional(dir, "memory.stat"); err != nil {
+		return err
+	} else if ok {
+		values, err := parseKeyValues(data)
+		if err != nil {
+			return err
+		}
+		stat.MemoryAnon = values["rss"]
+		stat.MemoryFile = values["cache"]
+		stat.MemoryShmem = values["shmem"]
+		stat.MemoryDirty = values["dirty"]
+		stat.MemoryWriteback = values["writeback"]
+		stat.PgFault = values["pgfault"]
+		stat.PgMajFault = values["pgmajfault"]
+	}
+	return nil
+}
+
+func (r *V1Reader) readBlkio(stat *CgroupStat, dir string) error {
+	if data, ok, err := readOptional(dir, "blkio.throttle.io_service_bytes"); err != nil {
+		return err
+	} else if ok {
+		read, write, err := parseBlkio(data)
+		if err != nil {
+			return err
+		}
+		stat.HasIO = true
+		stat.IOReadBytes, stat.IOWriteBytes = read, write
+	}
+
+	if data, ok, err := readOptional(dir, "blkio.throttle.io_serviced"); err != nil {
+		return err
+	} else if ok {
+		reads, writes, err := parseBlkio(data)
+		if err != nil {
+			return err
+		}
+		stat.HasIO = true
+		stat.IOReads, stat.IOWrites = reads, writes
+	}
+	return nil
+}
+
+func (r *V1Reader) readPids(stat *CgroupStat, dir string) error {
+	data, ok, err := readOptional(dir, "pids.current")
+	if err != nil || !ok {
+		return err
+	}
+	current, err := parseSingleValue(data)
+	if err != nil {
+		return err
+	}
+	stat.HasPids = true
+	stat.PidsCurrent = current
+	return nil
+}
+
+// parseBlkio sums Read and Write values of blkio throttle files across all
+// devices.
+func parseBlkio(data []byte) (read, write uint64, err error) {
+	p := &internal.ErrParser{}
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) != 3 {
+			continue
+		}
+		// fields[0] is the device number
+		switch fields[1] {
+		case "Read":
+			read += p.ParseUint64(fields[2])
+		case "Write":
+			write += p.ParseUint64(fields[2])
+		}
+	}
+	return read, write, p.Err()
+}
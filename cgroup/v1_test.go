@@ -0,0 +1,71 @@
+package cgroup_test
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/Bo0mer/yamt/cgroup"
+)
+
+func TestV1Reader(t *testing.T) {
+	r := cgroup.NewV1Reader("testdata/v1/mountinfo", regexp.MustCompile("^/docker/"), -1)
+	got, err := r.ReadStats()
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+
+	want := []cgroup.CgroupStat{
+		cgroup.CgroupStat{
+			Path:            "/docker/abc",
+			HasCPU:          true,
+			CPUUsageUsec:    150000,
+			CPUUserUsec:     100000,
+			CPUSystemUsec:   50000,
+			NrPeriods:       20,
+			NrThrottled:     3,
+			ThrottledUsec:   4500,
+			HasMemory:       true,
+			MemoryCurrent:   8388608,
+			MemoryAnon:      4194304,
+			MemoryFile:      2097152,
+			MemoryShmem:     4096,
+			MemoryDirty:     8192,
+			MemoryWriteback: 0,
+			PgFault:         1200,
+			PgMajFault:      3,
+			HasIO:           true,
+			IOReadBytes:     5120,
+			IOWriteBytes:    8192,
+			IOReads:         2,
+			IOWrites:        2,
+			HasPids:         true,
+			PidsCurrent:     5,
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v\n\tgot %v\n", want, got)
+	}
+}
+
+func TestV1Reader_merge(t *testing.T) {
+	r := cgroup.NewV1Reader("testdata/v1/mountinfo", nil, 0)
+	got, err := r.ReadStats()
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+
+	// Only the pids hierarchy root has any files, the rest are reported
+	// without values.
+	want := []cgroup.CgroupStat{
+		cgroup.CgroupStat{
+			Path:        "/",
+			HasPids:     true,
+			PidsCurrent: 120,
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v\n\tgot %v\n", want, got)
+	}
+}
@@ -0,0 +1,167 @@
+package cgroup
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Bo0mer/yamt/internal"
+	"github.com/Bo0mer/yamt/internal/hostfs"
+	"github.com/Bo0mer/yamt/psi"
+)
+
+// V2Reader reads statistics from a cgroup v2 (unified) hierarchy.
+type V2Reader struct {
+	root     string
+	match    *regexp.Regexp
+	maxDepth int
+}
+
+// NewV2Reader creates V2Reader that walks the hierarchy mounted at root.
+// Only cgroups whose path relative to root matches match are read; nil
+// matches all. Cgroups nested deeper than maxDepth below root are not
+// visited, the root itself being at depth zero. Negative maxDepth means no
+// limit. Paths under /sys are resolved relative to hostfs.SysRoot.
+func NewV2Reader(root string, match *regexp.Regexp, maxDepth int) *V2Reader {
+	return &V2Reader{
+		root:     root,
+		match:    match,
+		maxDepth: maxDepth,
+	}
+}
+
+// DefaultV2Reader is the default implementation of CgroupStatReader for
+// cgroup v2. It reads the root and its direct children from /sys/fs/cgroup.
+var DefaultV2Reader CgroupStatReader = NewV2Reader("/sys/fs/cgroup", nil, 1)
+
+// ReadStats reads statistics for all matching cgroups. Cgroups removed while
+// being read are skipped.
+func (r *V2Reader) ReadStats() ([]CgroupStat, error) {
+	root := hostfs.Resolve(r.root)
+	stats := make([]CgroupStat, 0)
+	err := walk(root, r.match, r.maxDepth, func(dir, cgroup string) error {
+		stat, err := r.readCgroup(dir, cgroup)
+		if err != nil {
+			return err
+		}
+		stats = append(stats, stat)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("readcgroupv2: error reading from %s: %v", root, err)
+	}
+	return stats, nil
+}
+
+func (r *V2Reader) readCgroup(dir, cgroup string) (CgroupStat, error) {
+	stat := CgroupStat{Path: cgroup}
+
+	if data, ok, err := readOptional(dir, "cpu.stat"); err != nil {
+		return CgroupStat{}, err
+	} else if ok {
+		values, err := parseKeyValues(data)
+		if err != nil {
+			return CgroupStat{}, err
+		}
+		stat.HasCPU = true
+		stat.CPUUsageUsec = values["usage_usec"]
+		stat.CPUUserUsec = values["user_usec"]
+		stat.CPUSystemUsec = values["system_usec"]
+		stat.NrPeriods = values["nr_periods"]
+		stat.NrThrottled = values["nr_throttled"]
+		stat.ThrottledUsec = values["throttled_usec"]
+	}
+
+	if data, ok, err := readOptional(dir, "memory.current"); err != nil {
+		return CgroupStat{}, err
+	} else if ok {
+		current, err := parseSingleValue(data)
+		if err != nil {
+			return CgroupStat{}, err
+		}
+		stat.HasMemory = true
+		stat.MemoryCurrent = current
+	}
+
+	if data, ok, err := readOptional(dir, "memory.stat"); err != nil {
+		return CgroupStat{}, err
+	} else if ok {
+		values, err := parseKeyValues(data)
+		if err != nil {
+			return CgroupStat{}, err
+		}
+		stat.MemoryAnon = values["anon"]
+		stat.MemoryFile = values["file"]
+		stat.MemoryShmem = values["shmem"]
+		stat.MemoryDirty = values["file_dirty"]
+		stat.MemoryWriteback = values["file_writeback"]
+		stat.PgFault = values["pgfault"]
+		stat.PgMajFault = values["pgmajfault"]
+	}
+
+	if data, ok, err := readOptional(dir, "io.stat"); err != nil {
+		return CgroupStat{}, err
+	} else if ok {
+		if err := r.parseIOStat(&stat, data); err != nil {
+			return CgroupStat{}, err
+		}
+		stat.HasIO = true
+	}
+
+	if data, ok, err := readOptional(dir, "pids.current"); err != nil {
+		return CgroupStat{}, err
+	} else if ok {
+		current, err := parseSingleValue(data)
+		if err != nil {
+			return CgroupStat{}, err
+		}
+		stat.HasPids = true
+		stat.PidsCurrent = current
+	}
+
+	for _, resource := range psi.Resources {
+		data, ok, err := readOptional(dir, resource+".pressure")
+		if err != nil {
+			return CgroupStat{}, err
+		}
+		if !ok {
+			continue
+		}
+		pressure, err := psi.ParseStat(resource, data)
+		if err != nil {
+			return CgroupStat{}, err
+		}
+		stat.Pressure = append(stat.Pressure, pressure)
+	}
+
+	return stat, nil
+}
+
+// parseIOStat sums io.stat values across all devices.
+func (r *V2Reader) parseIOStat(stat *CgroupStat, data []byte) error {
+	p := &internal.ErrParser{}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		// fields[0] is the device number
+		for _, field := range fields[1:] {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			switch kv[0] {
+			case "rbytes":
+				stat.IOReadBytes += p.ParseUint64(kv[1])
+			case "wbytes":
+				stat.IOWriteBytes += p.ParseUint64(kv[1])
+			case "rios":
+				stat.IOReads += p.ParseUint64(kv[1])
+			case "wios":
+				stat.IOWrites += p.ParseUint64(kv[1])
+			}
+		}
+	}
+	return p.Err()
+}
@@ -0,0 +1,125 @@
+package cgroup_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/Bo0mer/yamt/cgroup"
+	"github.com/Bo0mer/yamt/internal/hostfs"
+	"github.com/Bo0mer/yamt/psi"
+)
+
+func TestV2Reader(t *testing.T) {
+	r := cgroup.NewV2Reader("testdata/v2", regexp.MustCompile(`\.service$`), -1)
+	got, err := r.ReadStats()
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+
+	want := []cgroup.CgroupStat{
+		cgroup.CgroupStat{
+			Path:            "/system.slice/nginx.service",
+			HasCPU:          true,
+			CPUUsageUsec:    150000,
+			CPUUserUsec:     100000,
+			CPUSystemUsec:   50000,
+			NrPeriods:       20,
+			NrThrottled:     3,
+			ThrottledUsec:   4500,
+			HasMemory:       true,
+			MemoryCurrent:   8388608,
+			MemoryAnon:      4194304,
+			MemoryFile:      2097152,
+			MemoryShmem:     4096,
+			MemoryDirty:     8192,
+			MemoryWriteback: 0,
+			PgFault:         1200,
+			PgMajFault:      3,
+			HasIO:           true,
+			IOReadBytes:     5120,
+			IOWriteBytes:    8192,
+			IOReads:         2,
+			IOWrites:        2,
+			HasPids:         true,
+			PidsCurrent:     5,
+			Pressure: []psi.PressureStat{
+				psi.PressureStat{
+					Resource: "memory",
+					Some:     psi.Pressure{Avg10: 1.00, Avg60: 0.50, Avg300: 0.25, Total: 1000},
+					Full:     psi.Pressure{Avg10: 0.50, Avg60: 0.25, Avg300: 0.10, Total: 500},
+					HasFull:  true,
+				},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v\n\tgot %v\n", want, got)
+	}
+}
+
+func TestV2Reader_maxDepth(t *testing.T) {
+	cases := []struct {
+		maxDepth int
+		want     []string
+	}{
+		{0, []string{"/"}},
+		{1, []string{"/", "/system.slice"}},
+		{-1, []string{"/", "/system.slice", "/system.slice/nested", "/system.slice/nested/deep", "/system.slice/nginx.service"}},
+	}
+
+	for _, c := range cases {
+		r := cgroup.NewV2Reader("testdata/v2", nil, c.maxDepth)
+		stats, err := r.ReadStats()
+		if err != nil {
+			t.Fatalf("unexpected error: %v\n", err)
+		}
+		got := make([]string, 0, len(stats))
+		for _, stat := range stats {
+			got = append(got, stat.Path)
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("max depth %d: want %v\n\tgot %v\n", c.maxDepth, c.want, got)
+		}
+	}
+}
+
+func TestIsUnified(t *testing.T) {
+	if !cgroup.IsUnified("testdata/v2") {
+		t.Error("expected testdata/v2 to be unified hierarchy")
+	}
+	if cgroup.IsUnified("testdata/v1") {
+		t.Error("expected testdata/v1 not to be unified hierarchy")
+	}
+}
+
+func TestIsUnified_sysRoot(t *testing.T) {
+	defer func(sys string) { hostfs.SysRoot = sys }(hostfs.SysRoot)
+
+	dir, err := ioutil.TempDir("", "cgroup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	hostfs.SysRoot = dir
+
+	// The hierarchy of the host is looked up under the sys root, regardless
+	// of what is mounted at /sys/fs/cgroup of the running system.
+	root := filepath.Join(dir, "fs", "cgroup")
+	if err := os.MkdirAll(root, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if cgroup.IsUnified("/sys/fs/cgroup") {
+		t.Error("expected host hierarchy without cgroup.controllers not to be unified")
+	}
+	if err := ioutil.WriteFile(filepath.Join(root, "cgroup.controllers"), []byte("cpu io memory pids\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !cgroup.IsUnified("/sys/fs/cgroup") {
+		t.Error("expected host hierarchy with cgroup.controllers to be unified")
+	}
+}
@@ -0,0 +1,95 @@
+package conntrack
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Bo0mer/yamt/internal"
+	"github.com/Bo0mer/yamt/metric"
+)
+
+// ConntrackCollector computes metrics for the connection tracking table.
+type ConntrackCollector struct {
+	reader   ConntrackStatReader
+	last     ConntrackStat
+	lastTime time.Time
+}
+
+// NewConntrackCollector returns brand new connection tracking collector.
+func NewConntrackCollector(reader ConntrackStatReader) (*ConntrackCollector, error) {
+	c := &ConntrackCollector{
+		reader: reader,
+	}
+	if err := c.init(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Collect collects stats and creates events for the connection tracking
+// table. Per CPU counters are reported as rates, if read.
+func (c *ConntrackCollector) Collect() ([]metric.Event, error) {
+	actual, err := c.getState()
+	if err != nil {
+		return nil, err
+	}
+
+	actualTime := time.Now()
+	interval := actualTime.Sub(c.lastTime).Seconds()
+
+	events := c.buildEvents(actual, c.last, interval)
+
+	c.last = actual
+	c.lastTime = actualTime
+
+	return events, nil
+}
+
+// init loads the initial state of the collector.
+func (c *ConntrackCollector) init() error {
+	state, err := c.getState()
+	if err != nil {
+		return err
+	}
+	c.last = state
+	c.lastTime = time.Now()
+	return nil
+}
+
+// getState reads current state of the connection tracking table.
+func (c *ConntrackCollector) getState() (ConntrackStat, error) {
+	stat, err := c.reader.ReadStats()
+	if err != nil {
+		return ConntrackStat{}, fmt.Errorf("collector: error reading stats: %v", err)
+	}
+	return stat, nil
+}
+
+func (c *ConntrackCollector) buildEvents(actual, last ConntrackStat, interval float64) []metric.Event {
+	events := make([]metric.Event, 0)
+
+	var usage float64
+	if actual.Max > 0 {
+		usage = float64(actual.Count) / float64(actual.Max) * 100
+	}
+	events = append(events, event("count", float64(actual.Count)))
+	events = append(events, event("max", float64(actual.Max)))
+	events = append(events, event("usage(%)", usage))
+
+	if actual.HasCPUStats && last.HasCPUStats {
+		rate := internal.RateComputer(interval)
+		events = append(events, event("insert failed", rate(actual.InsertFailed, last.InsertFailed)))
+		events = append(events, event("drop", rate(actual.Drop, last.Drop)))
+		events = append(events, event("early drop", rate(actual.EarlyDrop, last.EarlyDrop)))
+		events = append(events, event("search restart", rate(actual.SearchRestart, last.SearchRestart)))
+	}
+
+	return events
+}
+
+func event(name string, value float64) metric.Event {
+	return metric.Event{
+		Name:  "conntrack " + name,
+		Value: value,
+	}
+}
@@ -0,0 +1,88 @@
+package conntrack_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Bo0mer/yamt/conntrack"
+	"github.com/Bo0mer/yamt/conntrack/conntrackfakes"
+	"github.com/Bo0mer/yamt/metric"
+)
+
+// Test that *ConntrackCollector implements metric.Collector
+var _ metric.Collector = (*conntrack.ConntrackCollector)(nil)
+
+func TestNewConntrackCollector(t *testing.T) {
+	errReader := new(conntrackfakes.FakeConntrackStatReader)
+	errReader.ReadStatsReturns(conntrack.ConntrackStat{}, errors.New("kaboom"))
+	_, err := conntrack.NewConntrackCollector(errReader)
+	if err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+func TestConntrackCollectorCollect(t *testing.T) {
+	reader := new(conntrackfakes.FakeConntrackStatReader)
+	reader.ReadStatsReturns(conntrack.ConntrackStat{Count: 1024, Max: 4096}, nil)
+
+	c, err := conntrack.NewConntrackCollector(reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	got, err := c.Collect()
+	if err != nil {
+		t.Errorf("unexpected error: %v\n", err)
+	}
+
+	want := []metric.Event{
+		metric.Event{Name: "conntrack count", Value: 1024.0},
+		metric.Event{Name: "conntrack max", Value: 4096.0},
+		metric.Event{Name: "conntrack usage(%)", Value: 25.0},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v\n", want, got)
+	}
+}
+
+func TestConntrackCollectorCollect_perCPU(t *testing.T) {
+	stats := []conntrack.ConntrackStat{
+		conntrack.ConntrackStat{Count: 10, Max: 10, HasCPUStats: true, Drop: 5},
+		conntrack.ConntrackStat{Count: 10, Max: 10, HasCPUStats: true, Drop: 15, EarlyDrop: 3},
+	}
+	reader := new(conntrackfakes.FakeConntrackStatReader)
+	i := 0
+	reader.ReadStatsStub = func() (conntrack.ConntrackStat, error) {
+		ret := stats[i]
+		i++
+		return ret, nil
+	}
+
+	c, err := conntrack.NewConntrackCollector(reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	got, err := c.Collect()
+	if err != nil {
+		t.Errorf("unexpected error: %v\n", err)
+	}
+
+	if len(got) != 7 {
+		t.Fatalf("want 7 events, got %d\n", len(got))
+	}
+	if got[2].Value != 100.0 {
+		t.Errorf("want usage 100, got %v\n", got[2].Value)
+	}
+	wantNames := []string{"conntrack insert failed", "conntrack drop", "conntrack early drop", "conntrack search restart"}
+	for i, name := range wantNames {
+		if got[i+3].Name != name {
+			t.Errorf("want event %q, got %q\n", name, got[i+3].Name)
+		}
+	}
+	if got[3].Value.(float64) != 0 || got[6].Value.(float64) != 0 {
+		t.Errorf("want zero rates for unchanged counters, got %v\n", got)
+	}
+	if got[4].Value.(float64) <= 0 || got[5].Value.(float64) <= 0 {
+		t.Errorf("want positive rates for changed counters, got %v\n", got)
+	}
+}
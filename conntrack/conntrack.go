@@ -0,0 +1,140 @@
+package conntrack
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Bo0mer/yamt/internal"
+	"github.com/Bo0mer/yamt/internal/hostfs"
+)
+
+//go:generate counterfeiter . ConntrackStatReader
+
+// ConntrackStat represents usage of the netfilter connection tracking
+// table.
+type ConntrackStat struct {
+	// Number of tracked connections.
+	Count uint64
+	// Size of the connection tracking table.
+	Max uint64
+
+	// HasCPUStats is true if the counters below were read. They are summed
+	// across all CPUs.
+	HasCPUStats bool
+	// Entries which could not be inserted into the table.
+	InsertFailed uint64
+	// Packets dropped because of failed insertion.
+	Drop uint64
+	// Entries dropped to make room for new ones when the table was full.
+	EarlyDrop uint64
+	// Table lookups which had to be restarted due to hash table resizing.
+	SearchRestart uint64
+}
+
+// ConntrackStatReader should read connection tracking table usage.
+type ConntrackStatReader interface {
+	ReadStats() (ConntrackStat, error)
+}
+
+// ProcReader reads connection tracking table usage from procfs.
+type ProcReader struct {
+	dir    string
+	perCPU bool
+}
+
+// NewProcReader creates ProcReader that reads from the specified procfs
+// directory. If perCPU is true, per CPU counters are read from
+// net/stat/nf_conntrack as well. Paths under /proc are resolved relative to
+// hostfs.ProcRoot.
+func NewProcReader(dir string, perCPU bool) *ProcReader {
+	return &ProcReader{
+		dir:    dir,
+		perCPU: perCPU,
+	}
+}
+
+// DefaultProcReader is the default implementation of ConntrackStatReader.
+// It reads only the table usage from /proc/sys/net/netfilter.
+var DefaultProcReader ConntrackStatReader = NewProcReader("/proc", false)
+
+// ReadConntrackStats is shorthand for DefaultProcReader.ReadStats.
+func ReadConntrackStats() (ConntrackStat, error) {
+	return DefaultProcReader.ReadStats()
+}
+
+// ReadStats reads connection tracking table usage.
+func (r *ProcReader) ReadStats() (ConntrackStat, error) {
+	stat := ConntrackStat{}
+
+	// Paths are resolved one by one, as net is resolved differently from
+	// the rest of procfs, see hostfs.Resolve.
+	var err error
+	stat.Count, err = readValue(hostfs.Resolve(filepath.Join(r.dir, "sys/net/netfilter/nf_conntrack_count")))
+	if err != nil {
+		return ConntrackStat{}, err
+	}
+	stat.Max, err = readValue(hostfs.Resolve(filepath.Join(r.dir, "sys/net/netfilter/nf_conntrack_max")))
+	if err != nil {
+		return ConntrackStat{}, err
+	}
+
+	if r.perCPU {
+		path := hostfs.Resolve(filepath.Join(r.dir, "net/stat/nf_conntrack"))
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return ConntrackStat{}, fmt.Errorf("readconntrack: error reading from %s: %v", path, err)
+		}
+		if err := r.parseCPUStats(&stat, data); err != nil {
+			return ConntrackStat{}, fmt.Errorf("readconntrack: error parsing %s: %v", path, err)
+		}
+	}
+	return stat, nil
+}
+
+// parseCPUStats sums per CPU counters. The first line names the columns,
+// which differ between kernel versions, followed by a line of hexadecimal
+// values for each CPU.
+func (r *ProcReader) parseCPUStats(stat *ConntrackStat, data []byte) error {
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	header := strings.Fields(lines[0])
+	for i, line := range lines[1:] {
+		fields := strings.Fields(line)
+		if len(fields) != len(header) {
+			return fmt.Errorf("unexpected number of fields on line %d: %q", i+1, line)
+		}
+		for j, name := range header {
+			v, err := strconv.ParseUint(fields[j], 16, 64)
+			if err != nil {
+				return err
+			}
+			switch name {
+			case "insert_failed":
+				stat.InsertFailed += v
+			case "drop":
+				stat.Drop += v
+			case "early_drop":
+				stat.EarlyDrop += v
+			case "search_restart":
+				stat.SearchRestart += v
+			}
+		}
+	}
+	stat.HasCPUStats = true
+	return nil
+}
+
+func readValue(path string) (uint64, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("readconntrack: error reading from %s: %v", path, err)
+	}
+	p := &internal.ErrParser{}
+	v := p.ParseUint64(strings.TrimSpace(string(data)))
+	if err := p.Err(); err != nil {
+		return 0, fmt.Errorf("readconntrack: error parsing %s: %v", path, err)
+	}
+	return v, nil
+}
@@ -0,0 +1,52 @@
+package conntrack_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Bo0mer/yamt/conntrack"
+)
+
+func TestProcReader(t *testing.T) {
+	r := conntrack.NewProcReader("testdata/proc", false)
+	got, err := r.ReadStats()
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+
+	want := conntrack.ConntrackStat{
+		Count: 52143,
+		Max:   262144,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %#v, got %#v\n", want, got)
+	}
+}
+
+func TestProcReader_perCPU(t *testing.T) {
+	r := conntrack.NewProcReader("testdata/proc", true)
+	got, err := r.ReadStats()
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+
+	want := conntrack.ConntrackStat{
+		Count:         52143,
+		Max:           262144,
+		HasCPUStats:   true,
+		InsertFailed:  3,
+		Drop:          4,
+		EarlyDrop:     4,
+		SearchRestart: 17,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %#v, got %#v\n", want, got)
+	}
+}
+
+func TestProcReader_missing(t *testing.T) {
+	r := conntrack.NewProcReader("testdata/missing", false)
+	if _, err := r.ReadStats(); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
@@ -0,0 +1,27 @@
+package conntrack
+
+import (
+	"github.com/Bo0mer/yamt/metric"
+	"github.com/Bo0mer/yamt/registry"
+)
+
+func init() {
+	registry.Register(registry.Collector{
+		Name:        "conntrack",
+		Description: "Report netfilter connection tracking table usage",
+		Options: []registry.Option{
+			{Name: "conntrack-cpu", Kind: registry.Bool, Usage: "Report per CPU connection tracking counters"},
+		},
+		New: func(c registry.Config) ([]metric.Collector, error) {
+			perCPU, err := c.Bool("conntrack-cpu")
+			if err != nil {
+				return nil, err
+			}
+			cc, err := NewConntrackCollector(NewProcReader("/proc", perCPU))
+			if err != nil {
+				return nil, err
+			}
+			return []metric.Collector{metric.Named("conntrack", cc)}, nil
+		},
+	})
+}
@@ -0,0 +1,90 @@
+package hwmon
+
+import (
+	"fmt"
+
+	"github.com/Bo0mer/yamt/metric"
+)
+
+// SensorCollector reports hardware sensor readings.
+type SensorCollector struct {
+	reader SensorStatReader
+}
+
+// NewSensorCollector returns brand new sensor collector.
+func NewSensorCollector(reader SensorStatReader) (*SensorCollector, error) {
+	c := &SensorCollector{
+		reader: reader,
+	}
+	if _, err := c.getState(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Collect reads all sensors and creates one event per sensor. Sensors which
+// reached their critical threshold are reported in critical state, the ones
+// which reached their maximum (or fell below their minimum for fans) in
+// warning state.
+func (c *SensorCollector) Collect() ([]metric.Event, error) {
+	sensors, err := c.getState()
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]metric.Event, 0, len(sensors))
+	for _, sensor := range sensors {
+		events = append(events, c.buildEvent(sensor))
+	}
+	return events, nil
+}
+
+// getState reads current readings of all sensors.
+func (c *SensorCollector) getState() ([]Sensor, error) {
+	sensors, err := c.reader.ReadStats()
+	if err != nil {
+		return nil, fmt.Errorf("collector: error reading stats: %v", err)
+	}
+	return sensors, nil
+}
+
+// buildEvent builds the event for a single sensor.
+func (c *SensorCollector) buildEvent(s Sensor) metric.Event {
+	return metric.Event{
+		Name:  fmt.Sprintf("sensor %s %s %s", s.Chip, s.Name, unit(s.Kind)),
+		Value: s.Value,
+		State: state(s),
+		Attributes: map[string]string{
+			"chip":   s.Chip,
+			"sensor": s.Name,
+		},
+	}
+}
+
+// state returns the state of a sensor based on its kernel reported
+// thresholds.
+func state(s Sensor) string {
+	switch {
+	case s.HasCrit && s.Value >= s.Crit:
+		return metric.StateCritical
+	case s.Kind == Fan && s.HasMin && s.Value < s.Min:
+		return metric.StateWarning
+	case s.Kind != Fan && s.HasMax && s.Value >= s.Max:
+		return metric.StateWarning
+	}
+	return metric.StateOK
+}
+
+func unit(kind string) string {
+	switch kind {
+	case Temperature:
+		return "temp(C)"
+	case Fan:
+		return "fan(rpm)"
+	case Voltage:
+		return "voltage(V)"
+	case Power:
+		return "power(W)"
+	}
+	return kind
+}
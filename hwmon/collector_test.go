@@ -0,0 +1,55 @@
+package hwmon_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Bo0mer/yamt/hwmon"
+	"github.com/Bo0mer/yamt/hwmon/hwmonfakes"
+	"github.com/Bo0mer/yamt/metric"
+)
+
+// Test that *SensorCollector implements metric.Collector
+var _ metric.Collector = (*hwmon.SensorCollector)(nil)
+
+func TestNewSensorCollector(t *testing.T) {
+	errReader := new(hwmonfakes.FakeSensorStatReader)
+	errReader.ReadStatsReturns(nil, errors.New("kaboom"))
+	_, err := hwmon.NewSensorCollector(errReader)
+	if err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+func TestSensorCollectorCollect(t *testing.T) {
+	reader := new(hwmonfakes.FakeSensorStatReader)
+	reader.ReadStatsReturns([]hwmon.Sensor{
+		hwmon.Sensor{Chip: "coretemp", Name: "Core 0", Kind: hwmon.Temperature, Value: 45, Crit: 100, HasCrit: true, Max: 80, HasMax: true},
+		hwmon.Sensor{Chip: "coretemp", Name: "Core 1", Kind: hwmon.Temperature, Value: 85, Crit: 100, HasCrit: true, Max: 80, HasMax: true},
+		hwmon.Sensor{Chip: "coretemp", Name: "Core 2", Kind: hwmon.Temperature, Value: 100, Crit: 100, HasCrit: true, Max: 80, HasMax: true},
+		hwmon.Sensor{Chip: "nct6775", Name: "fan1", Kind: hwmon.Fan, Value: 0, Min: 300, HasMin: true},
+	}, nil)
+
+	attributes := func(chip, sensor string) map[string]string {
+		return map[string]string{"chip": chip, "sensor": sensor}
+	}
+	want := []metric.Event{
+		metric.Event{Name: "sensor coretemp Core 0 temp(C)", Value: 45.0, State: metric.StateOK, Attributes: attributes("coretemp", "Core 0")},
+		metric.Event{Name: "sensor coretemp Core 1 temp(C)", Value: 85.0, State: metric.StateWarning, Attributes: attributes("coretemp", "Core 1")},
+		metric.Event{Name: "sensor coretemp Core 2 temp(C)", Value: 100.0, State: metric.StateCritical, Attributes: attributes("coretemp", "Core 2")},
+		metric.Event{Name: "sensor nct6775 fan1 fan(rpm)", Value: 0.0, State: metric.StateWarning, Attributes: attributes("nct6775", "fan1")},
+	}
+
+	c, err := hwmon.NewSensorCollector(reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	got, err := c.Collect()
+	if err != nil {
+		t.Errorf("unexpected error: %v\n", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %#v\n\tgot %#v\n", want, got)
+	}
+}
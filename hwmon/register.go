@@ -0,0 +1,20 @@
+package hwmon
+
+import (
+	"github.com/Bo0mer/yamt/metric"
+	"github.com/Bo0mer/yamt/registry"
+)
+
+func init() {
+	registry.Register(registry.Collector{
+		Name:        "sensors",
+		Description: "Report hardware sensor metrics",
+		New: func(c registry.Config) ([]metric.Collector, error) {
+			sc, err := NewSensorCollector(DefaultSysfsReader)
+			if err != nil {
+				return nil, err
+			}
+			return []metric.Collector{metric.Named("sensors", sc)}, nil
+		},
+	})
+}
@@ -0,0 +1,265 @@
+package hwmon
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Bo0mer/yamt/internal/hostfs"
+)
+
+//go:generate counterfeiter . SensorStatReader
+
+// Sensor kinds.
+const (
+	Temperature = "temp"
+	Fan         = "fan"
+	Voltage     = "in"
+	Power       = "power"
+)
+
+// Sensor represents a single hardware sensor reading. Values are converted
+// to degrees Celsius, RPM, volts and watts depending on the sensor kind.
+type Sensor struct {
+	// Chip is the name of the hwmon chip, or of the thermal zone.
+	Chip string
+	// Name is the sensor label, if provided by the driver, or its kernel
+	// name, e.g. temp1.
+	Name string
+	// Kind is one of Temperature, Fan, Voltage or Power.
+	Kind string
+
+	Value float64
+
+	// Critical threshold reported by the kernel.
+	Crit    float64
+	HasCrit bool
+	// Maximum threshold reported by the kernel.
+	Max    float64
+	HasMax bool
+	// Minimum threshold reported by the kernel, used for fans.
+	Min    float64
+	HasMin bool
+}
+
+// SensorStatReader should read all available hardware sensors.
+type SensorStatReader interface {
+	ReadStats() ([]Sensor, error)
+}
+
+// SysfsReader reads hwmon sensors and thermal zones from sysfs.
+type SysfsReader struct {
+	classDir string
+}
+
+// NewSysfsReader creates SysfsReader that reads the hwmon and thermal classes
+// from the specified directory. Paths under /sys are resolved relative to
+// hostfs.SysRoot.
+func NewSysfsReader(classDir string) *SysfsReader {
+	return &SysfsReader{
+		classDir: classDir,
+	}
+}
+
+// DefaultSysfsReader is the default implementation of SensorStatReader.
+// It reads sensors from /sys/class/hwmon and /sys/class/thermal.
+var DefaultSysfsReader SensorStatReader = NewSysfsReader("/sys/class")
+
+// ReadSensors is shorthand for DefaultSysfsReader.ReadStats.
+func ReadSensors() ([]Sensor, error) {
+	return DefaultSysfsReader.ReadStats()
+}
+
+// ReadStats reads all hwmon sensors and thermal zones. Sensors which can not
+// be read, e.g. because the hardware reports no data, are skipped.
+func (r *SysfsReader) ReadStats() ([]Sensor, error) {
+	classDir := hostfs.Resolve(r.classDir)
+	hwmon, err := r.readHwmon(filepath.Join(classDir, "hwmon"))
+	if err != nil {
+		return nil, err
+	}
+	thermal, err := r.readThermal(filepath.Join(classDir, "thermal"))
+	if err != nil {
+		return nil, err
+	}
+	return append(hwmon, thermal...), nil
+}
+
+// hwmonInput matches hwmon input files, e.g. temp1_input.
+var hwmonInput = regexp.MustCompile(`^(temp|fan|in|power)(\d+)_(input|average)$`)
+
+func (r *SysfsReader) readHwmon(dir string) ([]Sensor, error) {
+	chips, err := readDirNames(dir, "hwmon")
+	if err != nil {
+		return nil, fmt.Errorf("readsensors: error reading from %s: %v", dir, err)
+	}
+
+	sensors := make([]Sensor, 0)
+	seen := make(map[string]int)
+	for _, chip := range chips {
+		chipDir := filepath.Join(dir, chip)
+		name, err := readString(filepath.Join(chipDir, "name"))
+		if err != nil {
+			name = chip
+		}
+		// Tell apart multiple instances of the same chip, e.g. coretemp
+		// on multi socket machines.
+		if n := seen[name]; n > 0 {
+			seen[name]++
+			name = fmt.Sprintf("%s-%d", name, n)
+		} else {
+			seen[name] = 1
+		}
+
+		files, err := ioutil.ReadDir(chipDir)
+		if err != nil {
+			return nil, fmt.Errorf("readsensors: error reading from %s: %v", chipDir, err)
+		}
+		for _, file := range files {
+			m := hwmonInput.FindStringSubmatch(file.Name())
+			if m == nil {
+				continue
+			}
+			kind, prefix := m[1], m[1]+m[2]
+			if kind == Power && m[3] == "average" && exists(filepath.Join(chipDir, prefix+"_input")) {
+				continue
+			}
+			scale := hwmonScale(kind)
+			value, err := readValue(filepath.Join(chipDir, file.Name()), scale)
+			if err != nil {
+				continue
+			}
+			sensor := Sensor{Chip: name, Name: prefix, Kind: kind, Value: value}
+			if label, err := readString(filepath.Join(chipDir, prefix+"_label")); err == nil && label != "" {
+				sensor.Name = label
+			}
+			sensor.Crit, sensor.HasCrit = readThreshold(filepath.Join(chipDir, prefix+"_crit"), scale)
+			sensor.Max, sensor.HasMax = readThreshold(filepath.Join(chipDir, prefix+"_max"), scale)
+			sensor.Min, sensor.HasMin = readThreshold(filepath.Join(chipDir, prefix+"_min"), scale)
+			sensors = append(sensors, sensor)
+		}
+	}
+	return sensors, nil
+}
+
+func (r *SysfsReader) readThermal(dir string) ([]Sensor, error) {
+	zones, err := readDirNames(dir, "thermal_zone")
+	if err != nil {
+		return nil, fmt.Errorf("readsensors: error reading from %s: %v", dir, err)
+	}
+
+	sensors := make([]Sensor, 0, len(zones))
+	for _, zone := range zones {
+		zoneDir := filepath.Join(dir, zone)
+		value, err := readValue(filepath.Join(zoneDir, "temp"), 1000)
+		if err != nil {
+			continue
+		}
+		sensor := Sensor{Chip: zone, Name: zone, Kind: Temperature, Value: value}
+		if typ, err := readString(filepath.Join(zoneDir, "type")); err == nil && typ != "" {
+			sensor.Name = typ
+		}
+		for i := 0; ; i++ {
+			prefix := filepath.Join(zoneDir, fmt.Sprintf("trip_point_%d_", i))
+			typ, err := readString(prefix + "type")
+			if err != nil {
+				break
+			}
+			switch typ {
+			case "critical":
+				sensor.Crit, sensor.HasCrit = readThreshold(prefix+"temp", 1000)
+			case "hot":
+				sensor.Max, sensor.HasMax = readThreshold(prefix+"temp", 1000)
+			}
+		}
+		sensors = append(sensors, sensor)
+	}
+	return sensors, nil
+}
+
+// hwmonScale returns divisor converting raw hwmon values of the specified
+// kind to base units.
+func hwmonScale(kind string) float64 {
+	switch kind {
+	case Temperature, Voltage:
+		// millidegrees Celsius, millivolts
+		return 1000
+	case Power:
+		// microwatts
+		return 1000000
+	}
+	return 1
+}
+
+// readDirNames returns names of entries in dir starting with prefix, ordered
+// by their numeric index, e.g. hwmon2 before hwmon10. Missing dir is treated
+// as empty.
+func readDirNames(dir, prefix string) ([]string, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	names := make([]string, 0, len(infos))
+	for _, info := range infos {
+		if strings.HasPrefix(info.Name(), prefix) {
+			names = append(names, info.Name())
+		}
+	}
+	sort.Sort(byIndex{names, prefix})
+	return names, nil
+}
+
+// byIndex sorts names of class devices by the index following prefix.
+type byIndex struct {
+	names  []string
+	prefix string
+}
+
+func (s byIndex) Len() int      { return len(s.names) }
+func (s byIndex) Swap(i, j int) { s.names[i], s.names[j] = s.names[j], s.names[i] }
+func (s byIndex) Less(i, j int) bool {
+	a, errA := strconv.Atoi(strings.TrimPrefix(s.names[i], s.prefix))
+	b, errB := strconv.Atoi(strings.TrimPrefix(s.names[j], s.prefix))
+	if errA != nil || errB != nil || a == b {
+		return s.names[i] < s.names[j]
+	}
+	return a < b
+}
+
+func readString(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func readValue(path string, scale float64) (float64, error) {
+	s, err := readString(path)
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return float64(v) / scale, nil
+}
+
+func readThreshold(path string, scale float64) (float64, bool) {
+	v, err := readValue(path, scale)
+	return v, err == nil
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,35 @@
+package hwmon_test
+
+import (
+	"testing"
+
+	"github.com/Bo0mer/yamt/hwmon"
+)
+
+func TestSysfsReader(t *testing.T) {
+	r := hwmon.NewSysfsReader("testdata/class")
+	got, err := r.ReadStats()
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+
+	want := []hwmon.Sensor{
+		hwmon.Sensor{Chip: "coretemp", Name: "Package id 0", Kind: hwmon.Temperature, Value: 45, Crit: 100, HasCrit: true, Max: 80, HasMax: true},
+		hwmon.Sensor{Chip: "coretemp", Name: "Core 0", Kind: hwmon.Temperature, Value: 101, Crit: 100, HasCrit: true},
+		hwmon.Sensor{Chip: "nct6775", Name: "fan1", Kind: hwmon.Fan, Value: 1200, Min: 300, HasMin: true},
+		hwmon.Sensor{Chip: "nct6775", Name: "Vcore", Kind: hwmon.Voltage, Value: 1.224, Max: 1.3, HasMax: true},
+		hwmon.Sensor{Chip: "nct6775", Name: "power1", Kind: hwmon.Power, Value: 12.5},
+		hwmon.Sensor{Chip: "coretemp-1", Name: "temp1", Kind: hwmon.Temperature, Value: 50},
+		hwmon.Sensor{Chip: "coretemp-2", Name: "temp1", Kind: hwmon.Temperature, Value: 52},
+		hwmon.Sensor{Chip: "thermal_zone0", Name: "x86_pkg_temp", Kind: hwmon.Temperature, Value: 47, Crit: 105, HasCrit: true},
+	}
+
+	if len(want) != len(got) {
+		t.Fatalf("want %v\n\tgot %v\n", want, got)
+	}
+	for i, sensor := range got {
+		if sensor != want[i] {
+			t.Errorf("want %v\n\tgot %v\n", want[i], sensor)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+// Package hostfs resolves paths of procfs and sysfs files. It allows the
+// host filesystems to be mounted elsewhere, e.g. when running in a container
+// with the host /proc bind-mounted at /host/proc.
+package hostfs
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+var (
+	// ProcRoot is where the host procfs is mounted.
+	ProcRoot = "/proc"
+	// SysRoot is where the host sysfs is mounted.
+	SysRoot = "/sys"
+)
+
+// Resolve returns the path at which the specified host path is accessible.
+// Paths under /proc and /sys are resolved relative to ProcRoot and SysRoot
+// respectively, all other paths are returned unchanged. When the host
+// procfs is mounted elsewhere, /proc/net refers to the network namespace of
+// yamt, hence paths under it are resolved through the host init process.
+func Resolve(path string) string {
+	if rest, ok := under(path, "/proc/net"); ok && ProcRoot != "/proc" {
+		return filepath.Join(ProcRoot, "1/net", rest)
+	}
+	if rest, ok := under(path, "/proc"); ok {
+		return filepath.Join(ProcRoot, rest)
+	}
+	if rest, ok := under(path, "/sys"); ok {
+		return filepath.Join(SysRoot, rest)
+	}
+	return path
+}
+
+// under reports whether path is dir or lies beneath it, returning the
+// remainder of path.
+func under(path, dir string) (string, bool) {
+	if path == dir {
+		return "", true
+	}
+	if strings.HasPrefix(path, dir+"/") {
+		return path[len(dir):], true
+	}
+	return "", false
+}
+
+// MountsFile returns path of the specified mount related procfs file, e.g.
+// mountinfo, as seen by the host. When the host procfs is mounted
+// elsewhere, /proc/self refers to the mount namespace of yamt, hence the
+// file of the host init process is used.
+func MountsFile(name string) string {
+	if ProcRoot != "/proc" {
+		return "/proc/1/" + name
+	}
+	return "/proc/self/" + name
+}
@@ -0,0 +1,47 @@
+package hostfs_test
+
+import (
+	"testing"
+
+	"github.com/Bo0mer/yamt/internal/hostfs"
+)
+
+func TestResolve(t *testing.T) {
+	defer func(proc, sys string) {
+		hostfs.ProcRoot, hostfs.SysRoot = proc, sys
+	}(hostfs.ProcRoot, hostfs.SysRoot)
+
+	cases := []struct {
+		path string
+		want string
+	}{
+		{"/proc/diskstats", "/proc/diskstats"},
+		{"/sys/fs/cgroup", "/sys/fs/cgroup"},
+		{"testdata/procDiskstats", "testdata/procDiskstats"},
+	}
+	for _, c := range cases {
+		if got := hostfs.Resolve(c.path); got != c.want {
+			t.Errorf("expected %q, got %q\n", c.want, got)
+		}
+	}
+
+	hostfs.ProcRoot, hostfs.SysRoot = "/host/proc", "/host/sys"
+	cases = []struct {
+		path string
+		want string
+	}{
+		{"/proc", "/host/proc"},
+		{"/proc/diskstats", "/host/proc/diskstats"},
+		{"/proc/net/dev", "/host/proc/1/net/dev"},
+		{"/proc/network", "/host/proc/network"},
+		{"/sys/fs/cgroup", "/host/sys/fs/cgroup"},
+		{"/processes", "/processes"},
+		{"/run/nginx.pid", "/run/nginx.pid"},
+		{"testdata/procDiskstats", "testdata/procDiskstats"},
+	}
+	for _, c := range cases {
+		if got := hostfs.Resolve(c.path); got != c.want {
+			t.Errorf("expected %q, got %q\n", c.want, got)
+		}
+	}
+}
@@ -27,6 +27,15 @@ func (p *ErrParser) ParseUint64(s string) uint64 {
 	return u
 }
 
+// ParseFloat64 extends strconv.ParseFloat by preserving last occurred error.
+func (p *ErrParser) ParseFloat64(s string) float64 {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		p.err = err
+	}
+	return f
+}
+
 // Err returns the last error encountered by the parser.
 func (p *ErrParser) Err() error {
 	return p.err
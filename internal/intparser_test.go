@@ -22,6 +22,14 @@ func TestParseUint64(t *testing.T) {
 	}
 }
 
+func TestParseFloat64(t *testing.T) {
+	p := &internal.ErrParser{}
+	f := p.ParseFloat64("4.2")
+	if f != 4.2 {
+		t.Errorf("expected 4.2, got %f\n", f)
+	}
+}
+
 func TestErr(t *testing.T) {
 	p := &internal.ErrParser{}
 	_ = p.ParseInt("not int")
@@ -0,0 +1,104 @@
+package internal
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Bo0mer/yamt/metric"
+)
+
+// DeviceLifecycle tracks devices appearing and disappearing between
+// collections. Events reported for a device are expired once the device is
+// gone for longer than the grace period, so that backends forget them.
+type DeviceLifecycle struct {
+	grace   time.Duration
+	present map[string]bool
+	// reported holds events last reported for each device by name.
+	reported map[string]map[string]metric.Event
+	// gone holds when devices which are to be expired disappeared.
+	gone map[string]time.Time
+}
+
+// NewDeviceLifecycle returns lifecycle of the initially present devices.
+func NewDeviceLifecycle(grace time.Duration, initial []string) *DeviceLifecycle {
+	l := &DeviceLifecycle{
+		grace:    grace,
+		present:  make(map[string]bool),
+		reported: make(map[string]map[string]metric.Event),
+		gone:     make(map[string]time.Time),
+	}
+	for _, device := range initial {
+		l.present[device] = true
+	}
+	return l
+}
+
+// Reported records events reported for device, to be expired once the
+// device is gone.
+func (l *DeviceLifecycle) Reported(device string, events []metric.Event) {
+	reported, ok := l.reported[device]
+	if !ok {
+		reported = make(map[string]metric.Event)
+		l.reported[device] = reported
+	}
+	for _, event := range events {
+		reported[event.Name] = event
+	}
+}
+
+// Update records devices present at now. It returns "<device> appeared"
+// and "<device> disappeared" events for devices which came and went since
+// the last update, and events with StateExpired for each event reported
+// for devices gone for longer than the grace period.
+func (l *DeviceLifecycle) Update(devices []string, now time.Time) []metric.Event {
+	events := make([]metric.Event, 0)
+	present := make(map[string]bool, len(devices))
+	for _, device := range devices {
+		present[device] = true
+		if l.present[device] {
+			continue
+		}
+		delete(l.gone, device)
+		event := lifecycleEvent(device, "appeared")
+		l.Reported(device, []metric.Event{event})
+		events = append(events, event)
+	}
+	for device := range l.present {
+		if present[device] {
+			continue
+		}
+		event := lifecycleEvent(device, "disappeared")
+		l.Reported(device, []metric.Event{event})
+		l.gone[device] = now
+		events = append(events, event)
+	}
+	l.present = present
+
+	for device, since := range l.gone {
+		if now.Sub(since) < l.grace {
+			continue
+		}
+		reported := l.reported[device]
+		names := make([]string, 0, len(reported))
+		for name := range reported {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			event := reported[name]
+			event.State = metric.StateExpired
+			events = append(events, event)
+		}
+		delete(l.reported, device)
+		delete(l.gone, device)
+	}
+	return events
+}
+
+func lifecycleEvent(device, what string) metric.Event {
+	return metric.Event{
+		Name:       device + " " + what,
+		Value:      1.0,
+		Attributes: map[string]string{"device": device},
+	}
+}
@@ -0,0 +1,58 @@
+package internal_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Bo0mer/yamt/internal"
+	"github.com/Bo0mer/yamt/metric"
+)
+
+func TestDeviceLifecycle(t *testing.T) {
+	start := time.Now()
+	l := internal.NewDeviceLifecycle(time.Minute, []string{"eth0"})
+	if got := l.Update([]string{"eth0"}, start); len(got) != 0 {
+		t.Errorf("expected no events for initial devices, got %v\n", got)
+	}
+
+	veth := map[string]string{"device": "veth0"}
+	got := l.Update([]string{"eth0", "veth0"}, start)
+	want := []metric.Event{{Name: "veth0 appeared", Value: 1.0, Attributes: veth}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v\n", want, got)
+	}
+	l.Reported("veth0", []metric.Event{{Name: "veth0 rx bytes", Value: 42.0}})
+
+	got = l.Update([]string{"eth0"}, start.Add(time.Second))
+	want = []metric.Event{{Name: "veth0 disappeared", Value: 1.0, Attributes: veth}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v\n", want, got)
+	}
+	if got := l.Update([]string{"eth0"}, start.Add(30*time.Second)); len(got) != 0 {
+		t.Errorf("expected no events within grace period, got %v\n", got)
+	}
+
+	got = l.Update([]string{"eth0"}, start.Add(time.Minute+time.Second))
+	want = []metric.Event{
+		{Name: "veth0 appeared", Value: 1.0, State: metric.StateExpired, Attributes: veth},
+		{Name: "veth0 disappeared", Value: 1.0, State: metric.StateExpired, Attributes: veth},
+		{Name: "veth0 rx bytes", Value: 42.0, State: metric.StateExpired},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v\n", want, got)
+	}
+	if got := l.Update([]string{"eth0"}, start.Add(time.Hour)); len(got) != 0 {
+		t.Errorf("expected events to be expired once, got %v\n", got)
+	}
+}
+
+func TestDeviceLifecycle_reappeared(t *testing.T) {
+	start := time.Now()
+	l := internal.NewDeviceLifecycle(time.Minute, []string{"sdb"})
+	l.Update(nil, start)
+	l.Update([]string{"sdb"}, start.Add(time.Second))
+	if got := l.Update([]string{"sdb"}, start.Add(time.Hour)); len(got) != 0 {
+		t.Errorf("expected device back within grace period not to expire, got %v\n", got)
+	}
+}
@@ -0,0 +1,90 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Bo0mer/yamt/metric"
+)
+
+// RollupGroup is a named group of devices whose metrics are summed. A group
+// without Match contains all devices.
+type RollupGroup struct {
+	Name  string
+	Match *regexp.Regexp
+}
+
+// ParseRollupGroup parses group in name=regexp format.
+func ParseRollupGroup(value string) (RollupGroup, error) {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return RollupGroup{}, fmt.Errorf("expected name=regexp, got %q", value)
+	}
+	match, err := regexp.Compile(parts[1])
+	if err != nil {
+		return RollupGroup{}, err
+	}
+	return RollupGroup{Name: parts[0], Match: match}, nil
+}
+
+// Rollup sums metrics of devices across groups.
+type Rollup struct {
+	groups []RollupGroup
+	sums   []map[string]float64
+	// metrics keeps metric names in the order they were first added.
+	metrics []string
+	seen    map[string]bool
+}
+
+// NewRollup returns rollup of the specified groups.
+func NewRollup(groups []RollupGroup) *Rollup {
+	r := &Rollup{
+		groups: groups,
+		sums:   make([]map[string]float64, len(groups)),
+		seen:   make(map[string]bool),
+	}
+	for i := range r.sums {
+		r.sums[i] = make(map[string]float64)
+	}
+	return r
+}
+
+// Add adds value of the named metric of device to the groups containing
+// the device.
+func (r *Rollup) Add(device, name string, value float64) {
+	if !r.seen[name] {
+		r.seen[name] = true
+		r.metrics = append(r.metrics, name)
+	}
+	for i, g := range r.groups {
+		if g.Match == nil || g.Match.MatchString(device) {
+			r.sums[i][name] += value
+		}
+	}
+}
+
+// Events returns events with the sums, named after the group and the
+// metric, e.g. "total rx bytes", with a group attribute. Groups which
+// contain no devices are not reported.
+func (r *Rollup) Events() []metric.Event {
+	events := make([]metric.Event, 0)
+	for i, g := range r.groups {
+		if len(r.sums[i]) == 0 {
+			continue
+		}
+		attributes := map[string]string{"group": g.Name}
+		for _, name := range r.metrics {
+			value, ok := r.sums[i][name]
+			if !ok {
+				continue
+			}
+			events = append(events, metric.Event{
+				Name:       g.Name + " " + name,
+				Value:      value,
+				Attributes: attributes,
+			})
+		}
+	}
+	return events
+}
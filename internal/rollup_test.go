@@ -0,0 +1,50 @@
+package internal_test
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/Bo0mer/yamt/internal"
+	"github.com/Bo0mer/yamt/metric"
+)
+
+func TestRollup(t *testing.T) {
+	r := internal.NewRollup([]internal.RollupGroup{
+		{Name: "total"},
+		{Name: "bonds", Match: regexp.MustCompile("^bond")},
+		{Name: "none", Match: regexp.MustCompile("^wlan")},
+	})
+	r.Add("eth0", "rx bytes", 1)
+	r.Add("eth0", "tx bytes", 2)
+	r.Add("bond0", "rx bytes", 10)
+	r.Add("bond0", "tx bytes", 20)
+	r.Add("bond1", "rx bytes", 100)
+
+	total := map[string]string{"group": "total"}
+	bonds := map[string]string{"group": "bonds"}
+	want := []metric.Event{
+		{Name: "total rx bytes", Value: 111.0, Attributes: total},
+		{Name: "total tx bytes", Value: 22.0, Attributes: total},
+		{Name: "bonds rx bytes", Value: 110.0, Attributes: bonds},
+		{Name: "bonds tx bytes", Value: 20.0, Attributes: bonds},
+	}
+	if got := r.Events(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v\n", want, got)
+	}
+}
+
+func TestParseRollupGroup(t *testing.T) {
+	g, err := internal.ParseRollupGroup("bonds=^bond")
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	if g.Name != "bonds" || g.Match.String() != "^bond" {
+		t.Errorf("expected bonds=^bond group, got %v\n", g)
+	}
+	for _, s := range []string{"bonds", "=^bond", "bonds=("} {
+		if _, err := internal.ParseRollupGroup(s); err == nil {
+			t.Errorf("expected error parsing %q\n", s)
+		}
+	}
+}
@@ -3,6 +3,9 @@ package iostat
 import (
 	"fmt"
 	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Bo0mer/yamt/internal"
@@ -11,18 +14,112 @@ import (
 
 type state map[string]DeviceStat
 
+// sorted returns stats sorted by name, so that events and sums across
+// devices do not depend on the map iteration order.
+func (s state) sorted() []DeviceStat {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	stats := make([]DeviceStat, 0, len(names))
+	for _, name := range names {
+		stats = append(stats, s[name])
+	}
+	return stats
+}
+
+// DeviceKind selects which devices are reported.
+type DeviceKind int
+
+const (
+	// AllDevices reports both whole disks and partitions.
+	AllDevices DeviceKind = iota
+	// Disks reports only whole disks.
+	Disks
+	// Partitions reports only partitions.
+	Partitions
+)
+
+type Option func(*DeviceStatCollector)
+
+// Metadata sets reader used to attach device metadata, e.g. device-mapper
+// name, size and rotational flag, to each event. Device-mapper devices are
+// reported by their device-mapper name instead of their kernel name.
+func Metadata(r DeviceInfoReader) Option {
+	return func(c *DeviceStatCollector) {
+		c.infoReader = r
+	}
+}
+
+// Devices sets which devices are reported. Telling partitions from whole
+// disks requires metadata, so it implies Metadata(DefaultSysfsReader) unless
+// Metadata is set explicitly.
+func Devices(kind DeviceKind) Option {
+	return func(c *DeviceStatCollector) {
+		c.kind = kind
+	}
+}
+
+// Total makes the collector report sums of the reported devices, named e.g.
+// "total writes bytes". Partitions and devices stacked on other ones, e.g.
+// device-mapper devices, are left out of the totals so that no IO is
+// counted twice. Telling them apart requires metadata, so it implies
+// Metadata(DefaultSysfsReader) unless Metadata is set explicitly.
+func Total() Option {
+	return func(c *DeviceStatCollector) {
+		c.total = true
+	}
+}
+
+// Group makes the collector report sums of the reported devices whose
+// reported name matches match, named after the group, e.g.
+// "nvme writes bytes".
+func Group(name string, match *regexp.Regexp) Option {
+	return func(c *DeviceStatCollector) {
+		c.groups = append(c.groups, internal.RollupGroup{Name: name, Match: match})
+	}
+}
+
+// Lifecycle makes the collector report devices which appeared or
+// disappeared since the last collection, and expire events of devices gone
+// for longer than grace, see metric.StateExpired.
+func Lifecycle(grace time.Duration) Option {
+	return func(c *DeviceStatCollector) {
+		c.trackLifecycle = true
+		c.grace = grace
+	}
+}
+
 type DeviceStatCollector struct {
 	reader   DeviceStatReader
 	except   *regexp.Regexp
 	last     state
 	lastTime time.Time
+
+	infoReader DeviceInfoReader
+	kind       DeviceKind
+	info       map[string]DeviceInfo
+
+	total  bool
+	groups []internal.RollupGroup
+
+	trackLifecycle bool
+	grace          time.Duration
+	lifecycle      *internal.DeviceLifecycle
 }
 
-func NewDeviceStatCollector(r DeviceStatReader, except *regexp.Regexp) (*DeviceStatCollector, error) {
+func NewDeviceStatCollector(r DeviceStatReader, except *regexp.Regexp, opts ...Option) (*DeviceStatCollector, error) {
 	c := &DeviceStatCollector{
 		reader: r,
 		except: except,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if (c.kind != AllDevices || c.total) && c.infoReader == nil {
+		c.infoReader = DefaultSysfsReader
+	}
 	if err := c.init(); err != nil {
 		return nil, err
 	}
@@ -39,17 +136,44 @@ func (c *DeviceStatCollector) Collect() ([]metric.Event, error) {
 	interval := actualTime.Sub(c.lastTime).Seconds()
 
 	events := make([]metric.Event, 0)
+	var totalGroups []internal.RollupGroup
+	if c.total {
+		totalGroups = append(totalGroups, internal.RollupGroup{Name: "total"})
+	}
+	total := internal.NewRollup(totalGroups)
+	groups := internal.NewRollup(c.groups)
+	names := make([]string, 0, len(actual))
 
-	for _, stat := range actual {
-		if c.except != nil && c.except.MatchString(stat.Name) {
+	for _, stat := range actual.sorted() {
+		info, ok := c.tracked(stat.Name)
+		if !ok {
 			continue
 		}
+		devName := reportedName(stat.Name, info)
+		names = append(names, devName)
 		last, ok := c.last[stat.Name]
 		if !ok {
 			continue
 		}
 
-		events = append(events, c.buildEvents(stat, last, interval)...)
+		devEvents := c.buildEvents(stat, last, info, interval)
+		physical := !info.Partition && len(info.Slaves) == 0
+		for _, e := range devEvents {
+			name, value := strings.TrimPrefix(e.Name, devName+" "), e.Value.(float64)
+			groups.Add(devName, name, value)
+			if physical {
+				total.Add(devName, name, value)
+			}
+		}
+		if c.lifecycle != nil {
+			c.lifecycle.Reported(devName, devEvents)
+		}
+		events = append(events, devEvents...)
+	}
+	events = append(events, total.Events()...)
+	events = append(events, groups.Events()...)
+	if c.lifecycle != nil {
+		events = append(events, c.lifecycle.Update(names, actualTime)...)
 	}
 
 	c.last = actual
@@ -65,9 +189,32 @@ func (c *DeviceStatCollector) init() error {
 	}
 	c.last = state
 	c.lastTime = time.Now()
+	if c.trackLifecycle {
+		// Devices present on start are not reported as appeared.
+		names := make([]string, 0, len(state))
+		for name := range state {
+			if info, ok := c.tracked(name); ok {
+				names = append(names, reportedName(name, info))
+			}
+		}
+		c.lifecycle = internal.NewDeviceLifecycle(c.grace, names)
+	}
 	return nil
 }
 
+// tracked tells whether the named device is reported, returning its
+// metadata if available.
+func (c *DeviceStatCollector) tracked(name string) (DeviceInfo, bool) {
+	if c.except != nil && c.except.MatchString(name) {
+		return DeviceInfo{}, false
+	}
+	info, ok := c.deviceInfo(name)
+	if ok && !c.reported(info) {
+		return DeviceInfo{}, false
+	}
+	return info, true
+}
+
 func (c *DeviceStatCollector) getState() (state, error) {
 	state := make(map[string]DeviceStat)
 	stats, err := c.reader.ReadStats()
@@ -77,22 +224,64 @@ func (c *DeviceStatCollector) getState() (state, error) {
 	for _, stat := range stats {
 		state[stat.Name] = stat
 	}
+	if c.info != nil {
+		// Forget metadata of removed devices, so that it is read again if
+		// a device with the same name appears.
+		for name := range c.info {
+			if _, ok := state[name]; !ok {
+				delete(c.info, name)
+			}
+		}
+	}
 	return state, nil
 }
 
-func (c *DeviceStatCollector) buildEvents(actual, last DeviceStat, interval float64) []metric.Event {
+// deviceInfo returns metadata for the named device, reading it on first
+// use. It returns false if metadata is disabled or could not be read.
+func (c *DeviceStatCollector) deviceInfo(name string) (DeviceInfo, bool) {
+	if c.infoReader == nil {
+		return DeviceInfo{}, false
+	}
+	if info, ok := c.info[name]; ok {
+		return info, true
+	}
+	info, err := c.infoReader.ReadInfo(name)
+	if err != nil {
+		return DeviceInfo{}, false
+	}
+	if c.info == nil {
+		c.info = make(map[string]DeviceInfo)
+	}
+	c.info[name] = info
+	return info, true
+}
+
+// reported tells whether device of the kind described by info is reported.
+func (c *DeviceStatCollector) reported(info DeviceInfo) bool {
+	switch c.kind {
+	case Disks:
+		return !info.Partition
+	case Partitions:
+		return info.Partition
+	}
+	return true
+}
+
+func (c *DeviceStatCollector) buildEvents(actual, last DeviceStat, info DeviceInfo, interval float64) []metric.Event {
 	events := make([]metric.Event, 0)
-	event := eventBuilder(actual.Name)
+	event := eventBuilder(actual.Name, info)
 	rate := internal.RateComputer(interval)
 
 	events = append(events, event("reads total", rate(actual.Reads, last.Reads)))
 	events = append(events, event("reads merged", rate(actual.ReadsMerged, last.ReadsMerged)))
 	events = append(events, event("reads sectors", rate(actual.ReadsSectors, last.ReadsSectors)))
+	events = append(events, event("reads bytes", rate(actual.ReadsSectors, last.ReadsSectors)*SectorSize))
 	events = append(events, event("reads time(ms)", rate(actual.ReadsTimeMs, last.ReadsTimeMs)))
 
 	events = append(events, event("writes total", rate(actual.Writes, last.Writes)))
 	events = append(events, event("writes merged", rate(actual.WritesMerged, last.WritesMerged)))
 	events = append(events, event("writes sectors", rate(actual.WritesSectors, last.WritesSectors)))
+	events = append(events, event("writes bytes", rate(actual.WritesSectors, last.WritesSectors)*SectorSize))
 	events = append(events, event("writes time(ms)", rate(actual.WritesTimeMs, last.WritesTimeMs)))
 
 	events = append(events, event("io inflight", float64(actual.InFlight)))
@@ -102,11 +291,47 @@ func (c *DeviceStatCollector) buildEvents(actual, last DeviceStat, interval floa
 	return events
 }
 
-func eventBuilder(devName string) func(string, float64) metric.Event {
+// reportedName returns name under which the device with the specified
+// kernel name is reported, i.e. its device-mapper name if it has one.
+func reportedName(name string, info DeviceInfo) string {
+	if info.DMName != "" {
+		return info.DMName
+	}
+	return name
+}
+
+func eventBuilder(devName string, info DeviceInfo) func(string, float64) metric.Event {
+	attributes := infoAttributes(info)
+	devName = reportedName(devName, info)
 	return func(name string, value float64) metric.Event {
 		return metric.Event{
-			Name:  devName + " " + name,
-			Value: value,
+			Name:       devName + " " + name,
+			Value:      value,
+			Attributes: attributes,
 		}
 	}
 }
+
+// infoAttributes returns event attributes describing the device, or nil if
+// there is no metadata.
+func infoAttributes(info DeviceInfo) map[string]string {
+	if info.Name == "" {
+		return nil
+	}
+	attributes := map[string]string{
+		"device":      info.Name,
+		"size":        strconv.FormatUint(info.SizeBytes, 10),
+		"rotational":  strconv.FormatBool(info.Rotational),
+		"sector_size": strconv.FormatUint(info.LogicalBlockSize, 10),
+	}
+	if info.DMName != "" {
+		attributes["dm_name"] = info.DMName
+	}
+	if len(info.Slaves) > 0 {
+		attributes["slaves"] = strings.Join(info.Slaves, ",")
+	}
+	if len(info.Holders) > 0 {
+		attributes["holders"] = strings.Join(info.Holders, ",")
+	}
+	return attributes
+}
@@ -3,6 +3,7 @@ package iostat_test
 import (
 	"errors"
 	"fmt"
+	"reflect"
 	"regexp"
 	"testing"
 
@@ -66,10 +67,12 @@ func TestDevStatCollectorCollect(t *testing.T) {
 		metric.Event{}, // reads total, handled separately
 		metric.Event{Name: "sda reads merged", Value: 0.0},
 		metric.Event{Name: "sda reads sectors", Value: 0.0},
+		metric.Event{Name: "sda reads bytes", Value: 0.0},
 		metric.Event{Name: "sda reads time(ms)", Value: 0.0},
 		metric.Event{Name: "sda writes total", Value: 0.0},
 		metric.Event{Name: "sda writes merged", Value: 0.0},
 		metric.Event{Name: "sda writes sectors", Value: 0.0},
+		metric.Event{Name: "sda writes bytes", Value: 0.0},
 		metric.Event{Name: "sda writes time(ms)", Value: 0.0},
 		metric.Event{Name: "sda io inflight", Value: 42.0},
 		metric.Event{Name: "sda io time(ms)", Value: 0.0},
@@ -101,7 +104,7 @@ func TestDevStatCollectorCollect(t *testing.T) {
 			}
 			continue
 		}
-		if got[i] != want[i] {
+		if !reflect.DeepEqual(got[i], want[i]) {
 			t.Errorf("expected %#v, got %#v\n", want[i], got[i])
 		}
 	}
@@ -123,3 +126,292 @@ func TestDevStatCollectorCollect_except(t *testing.T) {
 		t.Errorf("expected zero results, got %v\n", got)
 	}
 }
+
+func TestDevStatCollectorCollect_bytes(t *testing.T) {
+	samples := [][]iostat.DeviceStat{
+		{iostat.DeviceStat{Name: "sda", ReadsSectors: 100, WritesSectors: 1000}},
+		{iostat.DeviceStat{Name: "sda", ReadsSectors: 200, WritesSectors: 3000}},
+	}
+	reader := new(iostatfakes.FakeDeviceStatReader)
+	reader.ReadStatsStub = func() ([]iostat.DeviceStat, error) {
+		ret := samples[0]
+		samples = samples[1:]
+		return ret, nil
+	}
+
+	c, err := iostat.NewDeviceStatCollector(reader, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	got, err := c.Collect()
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	events := make(map[string]float64)
+	for _, e := range got {
+		events[e.Name] = e.Value.(float64)
+	}
+
+	for _, op := range []string{"reads", "writes"} {
+		sectors, bytes := events["sda "+op+" sectors"], events["sda "+op+" bytes"]
+		if sectors <= 0 || bytes != sectors*iostat.SectorSize {
+			t.Errorf("expected %s bytes to be %d times %f sectors, got %f\n", op, iostat.SectorSize, sectors, bytes)
+		}
+	}
+}
+
+func TestDevStatCollectorCollect_metadata(t *testing.T) {
+	reader := new(iostatfakes.FakeDeviceStatReader)
+	reader.ReadStatsReturns([]iostat.DeviceStat{
+		iostat.DeviceStat{Name: "sda"},
+		iostat.DeviceStat{Name: "sda2"},
+		iostat.DeviceStat{Name: "dm-0"},
+	}, nil)
+	infoReader := new(iostatfakes.FakeDeviceInfoReader)
+	infoReader.ReadInfoStub = func(name string) (iostat.DeviceInfo, error) {
+		switch name {
+		case "sda":
+			return iostat.DeviceInfo{Name: name, SizeBytes: 1024, Rotational: true, LogicalBlockSize: 512}, nil
+		case "dm-0":
+			return iostat.DeviceInfo{Name: name, DMName: "vg0-root", SizeBytes: 512, LogicalBlockSize: 4096, Slaves: []string{"sda2"}}, nil
+		}
+		return iostat.DeviceInfo{Name: name, Partition: true}, nil
+	}
+
+	c, err := iostat.NewDeviceStatCollector(reader, nil, iostat.Metadata(infoReader), iostat.Devices(iostat.Disks))
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	for i := 0; i < 2; i++ {
+		got, err := c.Collect()
+		if err != nil {
+			t.Fatalf("unexpected error: %v\n", err)
+		}
+		if len(got) != 26 {
+			t.Fatalf("expected 26 events, got %d\n", len(got))
+		}
+		events := make(map[string]metric.Event)
+		for _, e := range got {
+			events[e.Name] = e
+		}
+
+		want := metric.Event{
+			Name:  "sda reads total",
+			Value: 0.0,
+			Attributes: map[string]string{
+				"device":      "sda",
+				"size":        "1024",
+				"rotational":  "true",
+				"sector_size": "512",
+			},
+		}
+		if !reflect.DeepEqual(events[want.Name], want) {
+			t.Errorf("expected %#v, got %#v\n", want, events[want.Name])
+		}
+		want = metric.Event{
+			Name:  "vg0-root reads total",
+			Value: 0.0,
+			Attributes: map[string]string{
+				"device":      "dm-0",
+				"dm_name":     "vg0-root",
+				"size":        "512",
+				"rotational":  "false",
+				"sector_size": "4096",
+				"slaves":      "sda2",
+			},
+		}
+		if !reflect.DeepEqual(events[want.Name], want) {
+			t.Errorf("expected %#v, got %#v\n", want, events[want.Name])
+		}
+	}
+
+	// Metadata is read once per device.
+	if n := infoReader.ReadInfoCallCount(); n != 3 {
+		t.Errorf("expected 3 metadata reads, got %d\n", n)
+	}
+}
+
+func TestDevStatCollectorCollect_rollup(t *testing.T) {
+	reader := new(iostatfakes.FakeDeviceStatReader)
+	reader.ReadStatsReturns([]iostat.DeviceStat{
+		iostat.DeviceStat{Name: "sda", InFlight: 1},
+		iostat.DeviceStat{Name: "sda2", InFlight: 1},
+		iostat.DeviceStat{Name: "nvme0n1", InFlight: 10},
+		iostat.DeviceStat{Name: "nvme1n1", InFlight: 100},
+		iostat.DeviceStat{Name: "dm-0", InFlight: 1000},
+	}, nil)
+	infoReader := new(iostatfakes.FakeDeviceInfoReader)
+	infoReader.ReadInfoStub = func(name string) (iostat.DeviceInfo, error) {
+		switch name {
+		case "sda2":
+			return iostat.DeviceInfo{Name: name, Partition: true}, nil
+		case "dm-0":
+			return iostat.DeviceInfo{Name: name, DMName: "vg0-root", Slaves: []string{"sda2"}}, nil
+		}
+		return iostat.DeviceInfo{Name: name}, nil
+	}
+
+	c, err := iostat.NewDeviceStatCollector(reader, nil,
+		iostat.Metadata(infoReader),
+		iostat.Total(),
+		iostat.Group("nvme", regexp.MustCompile("^nvme")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	got, err := c.Collect()
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	if len(got) != 7*13 {
+		t.Fatalf("expected %d events, got %d\n", 7*13, len(got))
+	}
+	events := make(map[string]metric.Event)
+	for _, e := range got {
+		events[e.Name] = e
+	}
+
+	// Partitions and stacked devices are left out of the totals.
+	want := metric.Event{Name: "total io inflight", Value: 111.0, Attributes: map[string]string{"group": "total"}}
+	if !reflect.DeepEqual(events[want.Name], want) {
+		t.Errorf("expected %#v, got %#v\n", want, events[want.Name])
+	}
+	want = metric.Event{Name: "nvme io inflight", Value: 110.0, Attributes: map[string]string{"group": "nvme"}}
+	if !reflect.DeepEqual(events[want.Name], want) {
+		t.Errorf("expected %#v, got %#v\n", want, events[want.Name])
+	}
+}
+
+func TestDevStatCollectorCollect_total(t *testing.T) {
+	defer func(r iostat.DeviceInfoReader) { iostat.DefaultSysfsReader = r }(iostat.DefaultSysfsReader)
+	infoReader := new(iostatfakes.FakeDeviceInfoReader)
+	infoReader.ReadInfoStub = func(name string) (iostat.DeviceInfo, error) {
+		return iostat.DeviceInfo{Name: name, Partition: name == "sda1"}, nil
+	}
+	iostat.DefaultSysfsReader = infoReader
+
+	reader := new(iostatfakes.FakeDeviceStatReader)
+	reader.ReadStatsReturns([]iostat.DeviceStat{
+		iostat.DeviceStat{Name: "sda", InFlight: 3},
+		iostat.DeviceStat{Name: "sda1", InFlight: 3},
+	}, nil)
+
+	// Partitions are told apart without setting Metadata explicitly.
+	c, err := iostat.NewDeviceStatCollector(reader, nil, iostat.Total())
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	got, err := c.Collect()
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	events := make(map[string]metric.Event)
+	for _, e := range got {
+		events[e.Name] = e
+	}
+
+	want := metric.Event{Name: "total io inflight", Value: 3.0, Attributes: map[string]string{"group": "total"}}
+	if !reflect.DeepEqual(events[want.Name], want) {
+		t.Errorf("expected %#v, got %#v\n", want, events[want.Name])
+	}
+}
+
+func TestDevStatCollectorCollect_lifecycle(t *testing.T) {
+	samples := [][]iostat.DeviceStat{
+		{iostat.DeviceStat{Name: "sda"}},
+		{iostat.DeviceStat{Name: "sda"}, iostat.DeviceStat{Name: "sdb"}, iostat.DeviceStat{Name: "sdb1"}},
+		{iostat.DeviceStat{Name: "sda"}},
+	}
+	reader := new(iostatfakes.FakeDeviceStatReader)
+	reader.ReadStatsStub = func() ([]iostat.DeviceStat, error) {
+		ret := samples[0]
+		samples = samples[1:]
+		return ret, nil
+	}
+	infoReader := new(iostatfakes.FakeDeviceInfoReader)
+	infoReader.ReadInfoStub = func(name string) (iostat.DeviceInfo, error) {
+		return iostat.DeviceInfo{Name: name, Partition: name == "sdb1"}, nil
+	}
+
+	c, err := iostat.NewDeviceStatCollector(reader, nil,
+		iostat.Metadata(infoReader),
+		iostat.Devices(iostat.Disks),
+		iostat.Lifecycle(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+
+	got, err := c.Collect()
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	want := metric.Event{Name: "sdb appeared", Value: 1.0, Attributes: map[string]string{"device": "sdb"}}
+	if n := len(got); n != 14 || !reflect.DeepEqual(got[n-1], want) {
+		t.Errorf("expected events of sda and %v, got %v\n", want, got)
+	}
+
+	got, err = c.Collect()
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	expired := 0
+	for _, e := range got {
+		if e.State == metric.StateExpired {
+			expired++
+		}
+	}
+	// Only sdb was seen, but not reported as it had no previous sample.
+	if expired != 2 {
+		t.Errorf("expected sdb appeared and disappeared events to expire, got %v\n", got)
+	}
+}
+
+func TestDevStatCollectorCollect_lifecycleMetadata(t *testing.T) {
+	samples := [][]iostat.DeviceStat{
+		{iostat.DeviceStat{Name: "sda"}, iostat.DeviceStat{Name: "dm-0"}},
+		{iostat.DeviceStat{Name: "sda"}, iostat.DeviceStat{Name: "dm-0"}},
+		{iostat.DeviceStat{Name: "sda"}},
+	}
+	reader := new(iostatfakes.FakeDeviceStatReader)
+	reader.ReadStatsStub = func() ([]iostat.DeviceStat, error) {
+		ret := samples[0]
+		samples = samples[1:]
+		return ret, nil
+	}
+	infoReader := new(iostatfakes.FakeDeviceInfoReader)
+	infoReader.ReadInfoStub = func(name string) (iostat.DeviceInfo, error) {
+		if name == "dm-0" {
+			return iostat.DeviceInfo{Name: name, DMName: "vg0-root"}, nil
+		}
+		return iostat.DeviceInfo{Name: name}, nil
+	}
+
+	c, err := iostat.NewDeviceStatCollector(reader, nil,
+		iostat.Metadata(infoReader),
+		iostat.Lifecycle(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	if _, err := c.Collect(); err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	got, err := c.Collect()
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+
+	// Events are expired under the name they were reported with.
+	expired := make(map[string]bool)
+	for _, e := range got {
+		if e.State == metric.StateExpired {
+			expired[e.Name] = true
+		}
+	}
+	for _, name := range []string{"vg0-root disappeared", "vg0-root reads total", "vg0-root io inflight"} {
+		if !expired[name] {
+			t.Errorf("expected %q to expire, got %v\n", name, got)
+		}
+	}
+	if len(expired) != 14 {
+		t.Errorf("expected 14 expired events, got %d\n", len(expired))
+	}
+}
@@ -0,0 +1,138 @@
+package iostat
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Bo0mer/yamt/internal"
+	"github.com/Bo0mer/yamt/internal/hostfs"
+)
+
+//go:generate counterfeiter . DeviceInfoReader
+
+// DeviceInfo represents metadata about IO device.
+type DeviceInfo struct {
+	// Kernel device name, e.g. dm-3.
+	Name string
+	// Device-mapper name, e.g. vg0-root. Empty for other devices.
+	DMName string
+	// Partition is true for partitions and false for whole disks.
+	Partition bool
+	// Device size in bytes.
+	SizeBytes uint64
+	// Rotational is true for spinning disks.
+	Rotational bool
+	// Smallest unit the device can address, in bytes. Note that sectors in
+	// DeviceStat are always SectorSize bytes, regardless of this value.
+	LogicalBlockSize uint64
+	// Devices this device is built on, e.g. sda2 for an LVM volume.
+	Slaves []string
+	// Devices built on this device.
+	Holders []string
+}
+
+// DeviceInfoReader should read metadata about IO device by kernel name.
+type DeviceInfoReader interface {
+	ReadInfo(name string) (DeviceInfo, error)
+}
+
+// SysfsReader reads metadata about IO devices from sysfs.
+type SysfsReader struct {
+	dir string
+}
+
+// NewSysfsReader creates SysfsReader that reads from the specified block
+// class directory. Paths under /sys are resolved relative to
+// hostfs.SysRoot.
+func NewSysfsReader(dir string) *SysfsReader {
+	return &SysfsReader{
+		dir: dir,
+	}
+}
+
+// DefaultSysfsReader is the default implementation of DeviceInfoReader.
+// It reads IO device metadata from /sys/class/block.
+var DefaultSysfsReader DeviceInfoReader = NewSysfsReader("/sys/class/block")
+
+// ReadDeviceInfo is shorthand for DefaultSysfsReader.ReadInfo.
+func ReadDeviceInfo(name string) (DeviceInfo, error) {
+	return DefaultSysfsReader.ReadInfo(name)
+}
+
+// ReadInfo reads metadata about the device with the specified kernel name.
+// Partitions have no queue attributes of their own, so they are read from
+// the parent disk.
+func (r *SysfsReader) ReadInfo(name string) (DeviceInfo, error) {
+	dir, err := filepath.EvalSymlinks(filepath.Join(hostfs.Resolve(r.dir), name))
+	if err != nil {
+		return DeviceInfo{}, fmt.Errorf("readdevinfo: error resolving device %s: %v", name, err)
+	}
+
+	info := DeviceInfo{Name: name}
+	_, err = os.Stat(filepath.Join(dir, "partition"))
+	info.Partition = err == nil
+
+	queueDir := filepath.Join(dir, "queue")
+	if info.Partition {
+		queueDir = filepath.Join(filepath.Dir(dir), "queue")
+	}
+
+	p := &internal.ErrParser{}
+	size, err := readAttr(filepath.Join(dir, "size"))
+	if err != nil {
+		return DeviceInfo{}, err
+	}
+	// size is in SectorSize sectors, regardless of the logical block size.
+	info.SizeBytes = p.ParseUint64(size) * SectorSize
+
+	rotational, err := readAttr(filepath.Join(queueDir, "rotational"))
+	if err != nil {
+		return DeviceInfo{}, err
+	}
+	info.Rotational = rotational == "1"
+
+	blockSize, err := readAttr(filepath.Join(queueDir, "logical_block_size"))
+	if err != nil {
+		return DeviceInfo{}, err
+	}
+	info.LogicalBlockSize = p.ParseUint64(blockSize)
+
+	if err := p.Err(); err != nil {
+		return DeviceInfo{}, fmt.Errorf("readdevinfo: error parsing attributes of %s: %v", name, err)
+	}
+
+	if dmName, err := readAttr(filepath.Join(dir, "dm", "name")); err == nil {
+		info.DMName = dmName
+	}
+	info.Slaves = readNames(filepath.Join(dir, "slaves"))
+	info.Holders = readNames(filepath.Join(dir, "holders"))
+
+	return info, nil
+}
+
+func readAttr(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("readdevinfo: error reading from %s: %v", path, err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// readNames returns sorted names of the entries in dir, or nil if dir is
+// missing or empty.
+func readNames(dir string) []string {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil || len(entries) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,60 @@
+package iostat_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Bo0mer/yamt/iostat"
+)
+
+func TestSysfsReader(t *testing.T) {
+	r := iostat.NewSysfsReader("testdata/sys/class/block")
+
+	tests := []iostat.DeviceInfo{
+		iostat.DeviceInfo{
+			Name:             "sda",
+			SizeBytes:        976773168 * 512,
+			Rotational:       true,
+			LogicalBlockSize: 512,
+		},
+		iostat.DeviceInfo{
+			Name:             "sda2",
+			Partition:        true,
+			SizeBytes:        976771072 * 512,
+			Rotational:       true,
+			LogicalBlockSize: 512,
+			Holders:          []string{"dm-0"},
+		},
+		iostat.DeviceInfo{
+			Name:             "dm-0",
+			DMName:           "vg0-root",
+			SizeBytes:        209715200 * 512,
+			Rotational:       true,
+			LogicalBlockSize: 512,
+			Slaves:           []string{"sda2"},
+		},
+		iostat.DeviceInfo{
+			Name:             "nvme0n1",
+			SizeBytes:        1953525168 * 512,
+			LogicalBlockSize: 4096,
+		},
+	}
+
+	for _, want := range tests {
+		got, err := r.ReadInfo(want.Name)
+		if err != nil {
+			t.Errorf("unexpected error: %v\n", err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("want %#v, got %#v\n", want, got)
+		}
+	}
+}
+
+func TestSysfsReader_missing(t *testing.T) {
+	r := iostat.NewSysfsReader("testdata/sys/class/block")
+	if _, err := r.ReadInfo("sdz"); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
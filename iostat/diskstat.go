@@ -6,10 +6,15 @@ import (
 	"strings"
 
 	"github.com/Bo0mer/yamt/internal"
+	"github.com/Bo0mer/yamt/internal/hostfs"
 )
 
 //go:generate counterfeiter . DeviceStatReader
 
+// SectorSize is the size of sectors counted by the kernel, in bytes. It is
+// fixed, regardless of the logical block size of the device.
+const SectorSize = 512
+
 // DeviceStat represents statistics about IO device.
 type DeviceStat struct {
 	// Major device number.
@@ -63,6 +68,7 @@ type DevStatReader struct {
 }
 
 // NewDevStatReader creates DevStatReader that reads from the specified path.
+// Paths under /proc are resolved relative to hostfs.ProcRoot.
 // It expects well defined format and may cause panics if it is not present.
 func NewDevStatReader(path string) *DevStatReader {
 	return &DevStatReader{
@@ -79,15 +85,15 @@ func ReadDeviceStats() ([]DeviceStat, error) {
 	return DefaultDevStatReader.ReadStats()
 }
 
-// ReadDeviceStats reads statistics for all available disks.
-// It does so by reading from /proc/diskstats.
+// ReadStats reads statistics for all available disks.
+// It does so by reading from the path the reader was created with.
 func (r *DevStatReader) ReadStats() ([]DeviceStat, error) {
-	data, err := ioutil.ReadFile(r.path)
+	path := hostfs.Resolve(r.path)
+	data, err := ioutil.ReadFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("readdiskstats: error reading from /proc/diskstats: %v", err)
+		return nil, fmt.Errorf("readdiskstats: error reading from %s: %v", path, err)
 	}
 	return r.parseStats(data)
-	return nil, nil
 }
 
 func (r *DevStatReader) parseStats(data []byte) ([]DeviceStat, error) {
@@ -1,6 +1,7 @@
 package iostat_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/Bo0mer/yamt/iostat"
@@ -57,3 +58,14 @@ func TestReadDiskgot(t *testing.T) {
 		}
 	}
 }
+
+func TestReadDiskStats_missing(t *testing.T) {
+	r := iostat.NewDevStatReader("testdata/missing")
+	_, err := r.ReadStats()
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "testdata/missing") {
+		t.Errorf("expected error to report the path read, got %v\n", err)
+	}
+}
@@ -0,0 +1,78 @@
+// This file was generated by counterfeiter
+package iostatfakes
+
+import (
+	"sync"
+
+	"github.com/Bo0mer/yamt/iostat"
+)
+
+type FakeDeviceInfoReader struct {
+	ReadInfoStub        func(name string) (iostat.DeviceInfo, error)
+	readInfoMutex       sync.RWMutex
+	readInfoArgsForCall []struct {
+		name string
+	}
+	readInfoReturns struct {
+		result1 iostat.DeviceInfo
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeDeviceInfoReader) ReadInfo(name string) (iostat.DeviceInfo, error) {
+	fake.readInfoMutex.Lock()
+	fake.readInfoArgsForCall = append(fake.readInfoArgsForCall, struct {
+		name string
+	}{name})
+	fake.recordInvocation("ReadInfo", []interface{}{name})
+	fake.readInfoMutex.Unlock()
+	if fake.ReadInfoStub != nil {
+		return fake.ReadInfoStub(name)
+	} else {
+		return fake.readInfoReturns.result1, fake.readInfoReturns.result2
+	}
+}
+
+func (fake *FakeDeviceInfoReader) ReadInfoCallCount() int {
+	fake.readInfoMutex.RLock()
+	defer fake.readInfoMutex.RUnlock()
+	return len(fake.readInfoArgsForCall)
+}
+
+func (fake *FakeDeviceInfoReader) ReadInfoArgsForCall(i int) string {
+	fake.readInfoMutex.RLock()
+	defer fake.readInfoMutex.RUnlock()
+	return fake.readInfoArgsForCall[i].name
+}
+
+func (fake *FakeDeviceInfoReader) ReadInfoReturns(result1 iostat.DeviceInfo, result2 error) {
+	fake.ReadInfoStub = nil
+	fake.readInfoReturns = struct {
+		result1 iostat.DeviceInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDeviceInfoReader) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.readInfoMutex.RLock()
+	defer fake.readInfoMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeDeviceInfoReader) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ iostat.DeviceInfoReader = new(FakeDeviceInfoReader)
@@ -0,0 +1,78 @@
+package iostat
+
+import (
+	"fmt"
+
+	"github.com/Bo0mer/yamt/internal"
+	"github.com/Bo0mer/yamt/metric"
+	"github.com/Bo0mer/yamt/registry"
+)
+
+func init() {
+	registry.Register(registry.Collector{
+		Name:        "disk",
+		Description: "Report disk metrics",
+		Options: []registry.Option{
+			{Name: "ignore-devices", Short: "d", Usage: "Devices to exclude", Default: "ram|loop"},
+			{Name: "disk-metadata", Kind: registry.Bool, Usage: "Attach device metadata to disk metrics and report device-mapper devices by name"},
+			{Name: "disk-devices", Usage: "Disk devices to report: all, disks or partitions", Default: "all"},
+			{Name: "disk-total", Kind: registry.Bool, Usage: "Report disk metrics summed across disks, leaving out partitions and stacked devices"},
+			{Name: "disk-group", Kind: registry.Repeated, Usage: "Group of devices to report summed metrics for, in name=regexp format"},
+			registry.DeviceExpiry,
+		},
+		Sampled: true,
+		New:     newDeviceStatCollector,
+	})
+}
+
+func newDeviceStatCollector(c registry.Config) ([]metric.Collector, error) {
+	except, err := c.Regexp("ignore-devices")
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []Option
+	metadata, err := c.Bool("disk-metadata")
+	if err != nil {
+		return nil, err
+	}
+	if metadata {
+		opts = append(opts, Metadata(DefaultSysfsReader))
+	}
+	switch devices := c.String("disk-devices"); devices {
+	case "all":
+	case "disks":
+		opts = append(opts, Devices(Disks))
+	case "partitions":
+		opts = append(opts, Devices(Partitions))
+	default:
+		return nil, fmt.Errorf("invalid -disk-devices %q, expected all, disks or partitions", devices)
+	}
+	total, err := c.Bool("disk-total")
+	if err != nil {
+		return nil, err
+	}
+	if total {
+		opts = append(opts, Total())
+	}
+	for _, spec := range c.Strings("disk-group") {
+		g, err := internal.ParseRollupGroup(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -disk-group: %v", err)
+		}
+		opts = append(opts, Group(g.Name, g.Match))
+	}
+
+	expiry, err := c.Duration(registry.DeviceExpiry.Name)
+	if err != nil {
+		return nil, err
+	}
+	if expiry > 0 {
+		opts = append(opts, Lifecycle(expiry))
+	}
+	dc, err := NewDeviceStatCollector(DefaultDevStatReader, except, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return []metric.Collector{metric.Named("disk", dc)}, nil
+}
@@ -6,15 +6,29 @@ import (
 	"log"
 	"os"
 	"os/signal"
-	"regexp"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/Bo0mer/yamt/internal/flagvar"
-	"github.com/Bo0mer/yamt/iostat"
+	"github.com/Bo0mer/yamt/internal/hostfs"
 	"github.com/Bo0mer/yamt/metric"
 	"github.com/Bo0mer/yamt/metric/riemann"
-	"github.com/Bo0mer/yamt/netstat"
+	"github.com/Bo0mer/yamt/registry"
+
+	// Collector packages register their collectors on init.
+	_ "github.com/Bo0mer/yamt/cgroup"
+	_ "github.com/Bo0mer/yamt/conntrack"
+	_ "github.com/Bo0mer/yamt/hwmon"
+	_ "github.com/Bo0mer/yamt/iostat"
+	_ "github.com/Bo0mer/yamt/mdstat"
+	_ "github.com/Bo0mer/yamt/memstat"
+	_ "github.com/Bo0mer/yamt/netstat"
+	_ "github.com/Bo0mer/yamt/nfsstat"
+	_ "github.com/Bo0mer/yamt/procstat"
+	_ "github.com/Bo0mer/yamt/psi"
+	_ "github.com/Bo0mer/yamt/script"
+	_ "github.com/Bo0mer/yamt/sysstat"
 )
 
 var (
@@ -22,14 +36,28 @@ var (
 	port       int
 	eventHost  string
 	interval   int
+	timeout    time.Duration
 	tags       flagvar.Array
 	attributes flagvar.Map
+	procRoot   string
+	sysRoot    string
+
+	selfMetrics bool
+	selfPrefix  string
+
+	shutdownTimeout time.Duration
+
+	align bool
+	splay time.Duration
+
+	sampleInterval time.Duration
+	sampleStats    string
 
-	net       bool
-	ignoreIfs string
+	rulesFile string
 
-	disk          bool
-	ignoreDevices string
+	collectorSpecs flagvar.Array
+	listCollectors bool
+	config         *registry.Config
 )
 
 func init() {
@@ -41,48 +69,81 @@ func init() {
 	flag.StringVar(&eventHost, "event-host", "", "Event hostname")
 	flag.IntVar(&interval, "i", 5, "Seconds between updates (shorthand)")
 	flag.IntVar(&interval, "interval", 5, "Seconds between updates")
+	flag.DurationVar(&timeout, "collect-timeout", 0, "Deadline for a single collection, the interval if zero")
+	flag.BoolVar(&align, "align", false, "Align collections to wall-clock multiples of the interval")
+	flag.DurationVar(&splay, "splay", 0, "Maximum delay of collections, fixed per host to spread reports of many hosts")
+	flag.DurationVar(&shutdownTimeout, "shutdown-timeout", 5*time.Second, "Time to wait for running collections and emits on exit")
+	flag.DurationVar(&sampleInterval, "sample-interval", 0, "Sample metrics of collectors supporting it this often, see -list-collectors, reporting statistics over each interval, disabled if zero")
+	flag.StringVar(&sampleStats, "sample-stats", "min,max,mean,p95", "Comma separated statistics of sampled metrics: min, max, mean, p50, p95, p99 or stddev")
+	flag.StringVar(&rulesFile, "rules", "", "File with JSON rules for dropping, renaming and relabelling events")
+	flag.BoolVar(&selfMetrics, "self-metrics", false, "Report metrics about yamt itself")
+	flag.StringVar(&selfPrefix, "self-prefix", "yamt.", "Prefix of metrics about yamt itself")
 	flag.Var(&tags, "t", "Tag to add to events (shorthand)")
 	flag.Var(&tags, "tag", "Tag to add to events")
 	flag.Var(&attributes, "a", "Attribute to add to the events (shorthand)")
 	flag.Var(&attributes, "attribute", "Attribute to add to the events")
+	flag.StringVar(&procRoot, "proc-root", "/proc", "Mount point of the host procfs, /proc/sys/net is still read in the network namespace of yamt")
+	flag.StringVar(&sysRoot, "sys-root", "/sys", "Mount point of the host sysfs")
 
-	flag.BoolVar(&net, "net", false, "Report network interface metrics")
-	flag.StringVar(&ignoreIfs, "g", "lo", "Interfaces to ignore (shorthand)")
-	flag.StringVar(&ignoreIfs, "ignore-interfaces", "lo", "Interfaces to ignore")
-
-	flag.BoolVar(&disk, "disk", false, "Report disk metrics")
-	flag.StringVar(&ignoreDevices, "d", "ram|loop", "Devices to exclude")
-	flag.StringVar(&ignoreDevices, "ignore-devices", "ram|loop", "Devices to exclude")
+	flag.Var(&collectorSpecs, "collector", "Collector to enable, in name[@interval] format where interval overrides -interval, e.g. disk@1s")
+	flag.BoolVar(&listCollectors, "list-collectors", false, "List available collectors with their options and exit")
+	config = registry.Flags(flag.CommandLine)
 }
 
 func main() {
 	flag.Parse()
 
-	collectors := make([]metric.Collector, 0)
-	if net {
-		except, err := regexp.Compile(ignoreIfs)
+	if listCollectors {
+		registry.List(os.Stdout)
+		return
+	}
+
+	hostfs.ProcRoot = procRoot
+	hostfs.SysRoot = sysRoot
+
+	var stats []metric.Statistic
+	if sampleInterval > 0 {
+		var err error
+		stats, err = metric.ParseStatistics(sampleStats)
 		if err != nil {
-			log.Fatalf("yamt: invalid network interface regexp: %v\n", err)
+			log.Fatalf("yamt: invalid sample statistics: %v\n", err)
 		}
-		netCollector, err := netstat.NewIfStatCollector(netstat.DefaultIfStatReader, except)
+	}
+
+	// Collectors enabled by their own flags run every -interval, unless
+	// given by -collector with an interval as well.
+	names := config.Enabled()
+	intervals := make(map[string]time.Duration)
+	for _, spec := range collectorSpecs {
+		name, d, err := parseCollector(spec)
 		if err != nil {
-			log.Fatalf("yamt: error creating interface stats collector: %v\n", err)
+			log.Fatalf("yamt: invalid collector: %v\n", err)
 		}
-		collectors = append(collectors, netCollector)
-		log.Printf("yamt: attached network interface stats collector")
+		if _, ok := intervals[name]; !ok && !contains(names, name) {
+			names = append(names, name)
+		}
+		intervals[name] = d
 	}
 
-	if disk {
-		except, err := regexp.Compile(ignoreDevices)
+	collectors := make([]metric.Collector, 0)
+	var opts []metric.Option
+	for _, name := range names {
+		created, err := config.New(name)
 		if err != nil {
-			log.Fatalf("yamt: invalid io device regexp: %v\n", err)
+			log.Fatalf("yamt: %v\n", err)
 		}
-		ioCollector, err := iostat.NewDeviceStatCollector(iostat.DefaultDevStatReader, except)
-		if err != nil {
-			log.Fatalf("yamt: error creating io stats collector: %v\n", err)
+		reg, _ := registry.Lookup(name)
+		for _, c := range created {
+			if reg.Sampled && sampleInterval > 0 {
+				c = metric.Sampled(c, sampleInterval, stats...)
+			}
+			if d := intervals[name]; d > 0 {
+				opts = append(opts, metric.Schedule(c, d, 0))
+			} else {
+				collectors = append(collectors, c)
+			}
 		}
-		collectors = append(collectors, ioCollector)
-		log.Printf("yamt: attached io device stats collector")
+		log.Printf("yamt: attached %s collector", name)
 	}
 
 	log.Printf("yamt: sticking tags to events: %v\n", tags)
@@ -93,13 +154,78 @@ func main() {
 		riemann.Attributes(attributes))
 
 	d := time.Duration(interval) * time.Second
-	reporter := metric.NewReporter(emitter, collectors, metric.Interval(d))
+	opts = append(opts,
+		metric.Interval(d),
+		metric.Timeout(timeout),
+		metric.ShutdownTimeout(shutdownTimeout))
+	if align {
+		opts = append(opts, metric.Align())
+	}
+	if splay > 0 {
+		key := eventHost
+		if key == "" {
+			key, _ = os.Hostname()
+		}
+		opts = append(opts, metric.Splay(splay, key))
+	}
+	if selfMetrics {
+		opts = append(opts, metric.SelfMetrics(selfPrefix))
+	}
+	if rulesFile != "" {
+		rules, err := readRules(rulesFile)
+		if err != nil {
+			log.Fatalf("yamt: error reading rules: %v\n", err)
+		}
+		opts = append(opts, metric.Process(rules))
+		log.Printf("yamt: processing events with %d rules\n", len(rules))
+	}
+	reporter := metric.NewReporter(emitter, collectors, opts...)
 	reporter.Start()
-	defer reporter.Close()
 
 	log.Printf("yamt: started emitting metrics\n")
-	c := make(chan os.Signal)
+	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 	sig := <-c
 	fmt.Printf("yamt: exiting due to %s\n", sig)
+	if err := reporter.Close(); err != nil {
+		log.Fatalf("yamt: error shutting down: %v\n", err)
+	}
+}
+
+func readRules(path string) (metric.Rules, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return metric.ParseRules(f)
+}
+
+// parseCollector parses collector in name[@interval] format. Zero interval
+// is returned if none is given.
+func parseCollector(spec string) (string, time.Duration, error) {
+	name, interval := spec, ""
+	if at := strings.Index(spec, "@"); at >= 0 {
+		name, interval = spec[:at], spec[at+1:]
+	}
+	if _, ok := registry.Lookup(name); !ok {
+		return "", 0, fmt.Errorf("unknown collector %q, see -list-collectors", name)
+	}
+	if name == spec {
+		return name, 0, nil
+	}
+	d, err := time.ParseDuration(interval)
+	if err != nil || d <= 0 {
+		return "", 0, fmt.Errorf("invalid interval of %s collector: %q", name, interval)
+	}
+	return name, d, nil
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,104 @@
+package mdstat
+
+import (
+	"fmt"
+
+	"github.com/Bo0mer/yamt/metric"
+)
+
+// ArrayCollector reports the status of software RAID arrays.
+type ArrayCollector struct {
+	reader ArrayStatReader
+}
+
+// NewArrayCollector returns brand new software RAID collector.
+func NewArrayCollector(reader ArrayStatReader) (*ArrayCollector, error) {
+	c := &ArrayCollector{
+		reader: reader,
+	}
+	if _, err := c.getState(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Collect reads the status of all arrays and creates events for each of
+// them. Degraded and inactive arrays are reported in critical state.
+func (c *ArrayCollector) Collect() ([]metric.Event, error) {
+	arrays, err := c.getState()
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]metric.Event, 0)
+	for _, array := range arrays {
+		events = append(events, c.buildEvents(array)...)
+	}
+	return events, nil
+}
+
+// getState reads current status of all arrays.
+func (c *ArrayCollector) getState() ([]ArrayStat, error) {
+	arrays, err := c.reader.ReadStats()
+	if err != nil {
+		return nil, fmt.Errorf("collector: error reading stats: %v", err)
+	}
+	return arrays, nil
+}
+
+// buildEvents builds all events for a single array.
+func (c *ArrayCollector) buildEvents(a ArrayStat) []metric.Event {
+	var active, failed, spare int
+	for _, m := range a.Members {
+		switch {
+		case m.Failed:
+			failed++
+		case m.Spare:
+			spare++
+		default:
+			active++
+		}
+	}
+
+	state := metric.StateOK
+	if a.Degraded() || !a.Active {
+		state = metric.StateCritical
+	}
+	progress := 100.0
+	if a.SyncAction != "" {
+		progress = a.SyncProgress
+	}
+
+	events := make([]metric.Event, 0)
+	event := eventBuilder(a.Name)
+
+	events = append(events, event("active", boolValue(a.Active), state))
+	events = append(events, event("degraded", boolValue(a.Degraded()), state))
+	events = append(events, event("members active", float64(active), state))
+	events = append(events, event("members failed", float64(failed), state))
+	events = append(events, event("members spare", float64(spare), state))
+
+	events = append(events, event("sync progress(%)", progress, metric.StateOK))
+	events = append(events, event("sync speed(KB/s)", a.SyncSpeedKB, metric.StateOK))
+	events = append(events, event("sync finish(s)", a.SyncFinishMin*60, metric.StateOK))
+
+	return events
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func eventBuilder(array string) func(string, float64, string) metric.Event {
+	return func(name string, value float64, state string) metric.Event {
+		return metric.Event{
+			Name:       "mdstat " + array + " " + name,
+			Value:      value,
+			State:      state,
+			Attributes: map[string]string{"array": array},
+		}
+	}
+}
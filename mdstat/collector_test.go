@@ -0,0 +1,71 @@
+package mdstat_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Bo0mer/yamt/mdstat"
+	"github.com/Bo0mer/yamt/mdstat/mdstatfakes"
+	"github.com/Bo0mer/yamt/metric"
+)
+
+// Test that *ArrayCollector implements metric.Collector
+var _ metric.Collector = (*mdstat.ArrayCollector)(nil)
+
+func TestNewArrayCollector(t *testing.T) {
+	errReader := new(mdstatfakes.FakeArrayStatReader)
+	errReader.ReadStatsReturns(nil, errors.New("kaboom"))
+	_, err := mdstat.NewArrayCollector(errReader)
+	if err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+func TestArrayCollectorCollect(t *testing.T) {
+	reader := new(mdstatfakes.FakeArrayStatReader)
+	reader.ReadStatsReturns([]mdstat.ArrayStat{
+		mdstat.ArrayStat{
+			Name:   "md2",
+			Active: true,
+			Level:  "raid5",
+			Members: []mdstat.Member{
+				mdstat.Member{Name: "sdf1", Index: 4, Spare: true},
+				mdstat.Member{Name: "sde1", Index: 3, Failed: true},
+				mdstat.Member{Name: "sdd1", Index: 2},
+				mdstat.Member{Name: "sdc1", Index: 0},
+			},
+			Disks:         3,
+			DisksActive:   2,
+			Status:        "U_U",
+			SyncAction:    "recovery",
+			SyncProgress:  12.6,
+			SyncFinishMin: 2,
+			SyncSpeedKB:   184604,
+		},
+	}, nil)
+
+	attributes := map[string]string{"array": "md2"}
+	want := []metric.Event{
+		metric.Event{Name: "mdstat md2 active", Value: 1.0, State: metric.StateCritical, Attributes: attributes},
+		metric.Event{Name: "mdstat md2 degraded", Value: 1.0, State: metric.StateCritical, Attributes: attributes},
+		metric.Event{Name: "mdstat md2 members active", Value: 2.0, State: metric.StateCritical, Attributes: attributes},
+		metric.Event{Name: "mdstat md2 members failed", Value: 1.0, State: metric.StateCritical, Attributes: attributes},
+		metric.Event{Name: "mdstat md2 members spare", Value: 1.0, State: metric.StateCritical, Attributes: attributes},
+		metric.Event{Name: "mdstat md2 sync progress(%)", Value: 12.6, State: metric.StateOK, Attributes: attributes},
+		metric.Event{Name: "mdstat md2 sync speed(KB/s)", Value: 184604.0, State: metric.StateOK, Attributes: attributes},
+		metric.Event{Name: "mdstat md2 sync finish(s)", Value: 120.0, State: metric.StateOK, Attributes: attributes},
+	}
+
+	c, err := mdstat.NewArrayCollector(reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	got, err := c.Collect()
+	if err != nil {
+		t.Errorf("unexpected error: %v\n", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %#v\n\tgot %#v\n", want, got)
+	}
+}
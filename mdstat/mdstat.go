@@ -0,0 +1,188 @@
+package mdstat
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"github.com/Bo0mer/yamt/internal"
+	"github.com/Bo0mer/yamt/internal/hostfs"
+)
+
+//go:generate counterfeiter . ArrayStatReader
+
+// Member represents a device of a software RAID array.
+type Member struct {
+	// Device name, e.g. sda1.
+	Name string
+	// Role of the device in the array.
+	Index int
+	// Failed is true for faulty devices, marked by (F).
+	Failed bool
+	// Spare is true for spare devices, marked by (S).
+	Spare bool
+}
+
+// ArrayStat represents the status of a software RAID array.
+type ArrayStat struct {
+	// Array name, e.g. md0.
+	Name string
+	// Active is false for arrays which are assembled but not running.
+	Active bool
+	// RAID level, e.g. raid1. Empty for inactive arrays.
+	Level string
+	// Devices of the array, spares and failed ones included.
+	Members []Member
+
+	// Number of devices the array should have.
+	Disks int
+	// Number of devices currently in use.
+	DisksActive int
+	// Status of each device, U for up and _ for down, e.g. [U_U].
+	Status string
+
+	// Sync action in progress, e.g. resync or recovery. Empty when idle.
+	SyncAction string
+	// Progress of the sync action in percents.
+	SyncProgress float64
+	// Estimated time to finish the sync action, in minutes.
+	SyncFinishMin float64
+	// Speed of the sync action, in kilobytes per second.
+	SyncSpeedKB float64
+}
+
+// Degraded reports whether the array runs with less devices than it should.
+func (a ArrayStat) Degraded() bool {
+	return a.DisksActive < a.Disks || strings.Contains(a.Status, "_")
+}
+
+// ArrayStatReader should read the status of all software RAID arrays.
+type ArrayStatReader interface {
+	ReadStats() ([]ArrayStat, error)
+}
+
+// MdstatReader reads the status of software RAID arrays.
+type MdstatReader struct {
+	path string
+}
+
+// NewMdstatReader creates MdstatReader that reads from the specified path.
+// Paths under /proc are resolved relative to hostfs.ProcRoot.
+func NewMdstatReader(path string) *MdstatReader {
+	return &MdstatReader{
+		path: path,
+	}
+}
+
+// DefaultMdstatReader is the default implementation of ArrayStatReader.
+// It reads software RAID status from /proc/mdstat.
+var DefaultMdstatReader ArrayStatReader = NewMdstatReader("/proc/mdstat")
+
+// ReadArrayStats is shorthand for DefaultMdstatReader.ReadStats.
+func ReadArrayStats() ([]ArrayStat, error) {
+	return DefaultMdstatReader.ReadStats()
+}
+
+// ReadStats reads the status of all software RAID arrays.
+func (r *MdstatReader) ReadStats() ([]ArrayStat, error) {
+	path := hostfs.Resolve(r.path)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("readmdstat: error reading from %s: %v", path, err)
+	}
+	return r.parseStats(data)
+}
+
+var (
+	memberRe   = regexp.MustCompile(`^(.+)\[(\d+)\]((?:\([A-Z]\))*)$`)
+	disksRe    = regexp.MustCompile(`\[(\d+)/(\d+)\]`)
+	statusRe   = regexp.MustCompile(`\[([U_]+)\]`)
+	syncRe     = regexp.MustCompile(`(resync|recovery|reshape|check|repair)\s*=\s*([\d.]+)%`)
+	finishRe   = regexp.MustCompile(`finish=([\d.]+)min`)
+	speedRe    = regexp.MustCompile(`speed=(\d+)K/sec`)
+	arrayStart = regexp.MustCompile(`^md\S*\s+:`)
+)
+
+func (r *MdstatReader) parseStats(data []byte) ([]ArrayStat, error) {
+	stats := make([]ArrayStat, 0)
+	var current *ArrayStat
+	for i, line := range strings.Split(string(data), "\n") {
+		switch {
+		case arrayStart.MatchString(line):
+			if current != nil {
+				stats = append(stats, *current)
+			}
+			stat, err := r.parseArray(line)
+			if err != nil {
+				return nil, fmt.Errorf("readmdstat: error parsing line %d: %v", i, err)
+			}
+			current = &stat
+		case current != nil && strings.HasPrefix(line, " "):
+			if err := r.parseDetail(current, line); err != nil {
+				return nil, fmt.Errorf("readmdstat: error parsing line %d: %v", i, err)
+			}
+		}
+	}
+	if current != nil {
+		stats = append(stats, *current)
+	}
+	return stats, nil
+}
+
+// parseArray parses the first line describing an array, e.g.
+// md0 : active raid1 sdb1[1] sda1[0](F)
+func (r *MdstatReader) parseArray(line string) (ArrayStat, error) {
+	colon := strings.Index(line, ":")
+	stat := ArrayStat{Name: strings.TrimSpace(line[:colon])}
+
+	fields := strings.Fields(line[colon+1:])
+	if len(fields) == 0 {
+		return ArrayStat{}, fmt.Errorf("unsupported format: %q", line)
+	}
+	stat.Active = fields[0] == "active"
+
+	p := &internal.ErrParser{}
+	for _, field := range fields[1:] {
+		if strings.HasPrefix(field, "(") {
+			// e.g. (auto-read-only)
+			continue
+		}
+		m := memberRe.FindStringSubmatch(field)
+		if m == nil {
+			stat.Level = field
+			continue
+		}
+		stat.Members = append(stat.Members, Member{
+			Name:   m[1],
+			Index:  p.ParseInt(m[2]),
+			Failed: strings.Contains(m[3], "(F)"),
+			Spare:  strings.Contains(m[3], "(S)"),
+		})
+	}
+	return stat, p.Err()
+}
+
+// parseDetail parses lines following the first line of an array, which
+// hold device counts, status and sync progress.
+func (r *MdstatReader) parseDetail(stat *ArrayStat, line string) error {
+	p := &internal.ErrParser{}
+	if m := disksRe.FindStringSubmatch(line); m != nil {
+		stat.Disks = p.ParseInt(m[1])
+		stat.DisksActive = p.ParseInt(m[2])
+	}
+	if m := statusRe.FindStringSubmatch(line); m != nil {
+		stat.Status = m[1]
+	}
+	if m := syncRe.FindStringSubmatch(line); m != nil {
+		stat.SyncAction = m[1]
+		stat.SyncProgress = p.ParseFloat64(m[2])
+	}
+	if m := finishRe.FindStringSubmatch(line); m != nil {
+		stat.SyncFinishMin = p.ParseFloat64(m[1])
+	}
+	if m := speedRe.FindStringSubmatch(line); m != nil {
+		stat.SyncSpeedKB = p.ParseFloat64(m[1])
+	}
+	return p.Err()
+}
@@ -0,0 +1,84 @@
+package mdstat_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Bo0mer/yamt/mdstat"
+)
+
+func TestMdstatReader(t *testing.T) {
+	r := mdstat.NewMdstatReader("testdata/procMdstat")
+	got, err := r.ReadStats()
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+
+	want := []mdstat.ArrayStat{
+		mdstat.ArrayStat{
+			Name:   "md1",
+			Active: true,
+			Level:  "raid1",
+			Members: []mdstat.Member{
+				mdstat.Member{Name: "sdb2", Index: 1},
+				mdstat.Member{Name: "sda2", Index: 0},
+			},
+			Disks:       2,
+			DisksActive: 2,
+			Status:      "UU",
+		},
+		mdstat.ArrayStat{
+			Name:   "md2",
+			Active: true,
+			Level:  "raid5",
+			Members: []mdstat.Member{
+				mdstat.Member{Name: "sdf1", Index: 4, Spare: true},
+				mdstat.Member{Name: "sde1", Index: 3, Failed: true},
+				mdstat.Member{Name: "sdd1", Index: 2},
+				mdstat.Member{Name: "sdc1", Index: 0},
+			},
+			Disks:         3,
+			DisksActive:   2,
+			Status:        "U_U",
+			SyncAction:    "recovery",
+			SyncProgress:  12.6,
+			SyncFinishMin: 77.0,
+			SyncSpeedKB:   184604,
+		},
+		mdstat.ArrayStat{
+			Name:   "md0",
+			Active: true,
+			Level:  "raid1",
+			Members: []mdstat.Member{
+				mdstat.Member{Name: "sdb1", Index: 1},
+				mdstat.Member{Name: "sda1", Index: 0},
+			},
+			Disks:         2,
+			DisksActive:   2,
+			Status:        "UU",
+			SyncAction:    "resync",
+			SyncProgress:  27.4,
+			SyncFinishMin: 0.1,
+			SyncSpeedKB:   47872,
+		},
+		mdstat.ArrayStat{
+			Name:   "md3",
+			Active: false,
+			Members: []mdstat.Member{
+				mdstat.Member{Name: "sdg1", Index: 0, Spare: true},
+			},
+		},
+	}
+
+	if len(want) != len(got) {
+		t.Fatalf("want %v\n\tgot %v\n", want, got)
+	}
+	for i := range got {
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("want %v\n\tgot %v\n", want[i], got[i])
+		}
+	}
+	if got[0].Degraded() || !got[1].Degraded() {
+		t.Errorf("expected only md2 to be degraded\n")
+	}
+}
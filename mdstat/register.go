@@ -0,0 +1,20 @@
+package mdstat
+
+import (
+	"github.com/Bo0mer/yamt/metric"
+	"github.com/Bo0mer/yamt/registry"
+)
+
+func init() {
+	registry.Register(registry.Collector{
+		Name:        "raid",
+		Description: "Report software RAID status",
+		New: func(c registry.Config) ([]metric.Collector, error) {
+			ac, err := NewArrayCollector(DefaultMdstatReader)
+			if err != nil {
+				return nil, err
+			}
+			return []metric.Collector{metric.Named("raid", ac)}, nil
+		},
+	})
+}
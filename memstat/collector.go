@@ -0,0 +1,114 @@
+package memstat
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/Bo0mer/yamt/metric"
+)
+
+// SwapCollector reports usage of swap devices.
+type SwapCollector struct {
+	reader SwapStatReader
+}
+
+// NewSwapCollector returns brand new swap collector.
+func NewSwapCollector(reader SwapStatReader) (*SwapCollector, error) {
+	c := &SwapCollector{
+		reader: reader,
+	}
+	if _, err := c.getState(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Collect reads usage of all swap devices and creates events for each of
+// them.
+func (c *SwapCollector) Collect() ([]metric.Event, error) {
+	stats, err := c.getState()
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]metric.Event, 0)
+	for _, stat := range stats {
+		var usage float64
+		if stat.SizeKB > 0 {
+			usage = float64(stat.UsedKB) / float64(stat.SizeKB) * 100
+		}
+		attributes := map[string]string{
+			"swap": stat.Filename,
+			"type": stat.Type,
+		}
+		event := eventBuilder("swap "+stat.Filename, attributes)
+		events = append(events, event("size(bytes)", float64(stat.SizeKB*1024)))
+		events = append(events, event("used(bytes)", float64(stat.UsedKB*1024)))
+		events = append(events, event("usage(%)", usage))
+		events = append(events, event("priority", float64(stat.Priority)))
+	}
+	return events, nil
+}
+
+// getState reads current usage of all swap devices.
+func (c *SwapCollector) getState() ([]SwapStat, error) {
+	stats, err := c.reader.ReadStats()
+	if err != nil {
+		return nil, fmt.Errorf("collector: error reading stats: %v", err)
+	}
+	return stats, nil
+}
+
+// HugepageCollector reports usage of hugepages.
+type HugepageCollector struct {
+	reader HugepageStatReader
+}
+
+// NewHugepageCollector returns brand new hugepage collector.
+func NewHugepageCollector(reader HugepageStatReader) (*HugepageCollector, error) {
+	c := &HugepageCollector{
+		reader: reader,
+	}
+	if _, err := c.getState(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Collect reads usage of hugepages and creates events for each page size.
+func (c *HugepageCollector) Collect() ([]metric.Event, error) {
+	stats, err := c.getState()
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]metric.Event, 0)
+	for _, stat := range stats {
+		size := strconv.FormatUint(stat.PageSizeKB, 10) + "kB"
+		event := eventBuilder("hugepages "+size, map[string]string{"page_size": size})
+		events = append(events, event("total", float64(stat.Total)))
+		events = append(events, event("free", float64(stat.Free)))
+		events = append(events, event("reserved", float64(stat.Reserved)))
+		events = append(events, event("surplus", float64(stat.Surplus)))
+	}
+	return events, nil
+}
+
+// getState reads current usage of hugepages.
+func (c *HugepageCollector) getState() ([]HugepageStat, error) {
+	stats, err := c.reader.ReadStats()
+	if err != nil {
+		return nil, fmt.Errorf("collector: error reading stats: %v", err)
+	}
+	return stats, nil
+}
+
+func eventBuilder(prefix string, attributes map[string]string) func(string, float64) metric.Event {
+	return func(name string, value float64) metric.Event {
+		return metric.Event{
+			Name:       prefix + " " + name,
+			Value:      value,
+			Attributes: attributes,
+		}
+	}
+}
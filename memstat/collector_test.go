@@ -0,0 +1,133 @@
+package memstat_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Bo0mer/yamt/memstat"
+	"github.com/Bo0mer/yamt/memstat/memstatfakes"
+	"github.com/Bo0mer/yamt/metric"
+)
+
+// Test that collectors implement metric.Collector
+var (
+	_ metric.Collector = (*memstat.SwapCollector)(nil)
+	_ metric.Collector = (*memstat.HugepageCollector)(nil)
+	_ metric.Collector = (*memstat.NodeCollector)(nil)
+)
+
+func TestNewCollectors(t *testing.T) {
+	swapReader := new(memstatfakes.FakeSwapStatReader)
+	swapReader.ReadStatsReturns(nil, errors.New("kaboom"))
+	if _, err := memstat.NewSwapCollector(swapReader); err == nil {
+		t.Error("expected error, got nil")
+	}
+
+	hugepageReader := new(memstatfakes.FakeHugepageStatReader)
+	hugepageReader.ReadStatsReturns(nil, errors.New("kaboom"))
+	if _, err := memstat.NewHugepageCollector(hugepageReader); err == nil {
+		t.Error("expected error, got nil")
+	}
+
+	nodeReader := new(memstatfakes.FakeNodeStatReader)
+	nodeReader.ReadStatsReturns(nil, errors.New("kaboom"))
+	if _, err := memstat.NewNodeCollector(nodeReader); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+func TestSwapCollectorCollect(t *testing.T) {
+	reader := new(memstatfakes.FakeSwapStatReader)
+	reader.ReadStatsReturns([]memstat.SwapStat{
+		memstat.SwapStat{Filename: "/dev/sda3", Type: "partition", SizeKB: 1024, UsedKB: 256, Priority: -2},
+	}, nil)
+
+	attributes := map[string]string{"swap": "/dev/sda3", "type": "partition"}
+	want := []metric.Event{
+		metric.Event{Name: "swap /dev/sda3 size(bytes)", Value: 1048576.0, Attributes: attributes},
+		metric.Event{Name: "swap /dev/sda3 used(bytes)", Value: 262144.0, Attributes: attributes},
+		metric.Event{Name: "swap /dev/sda3 usage(%)", Value: 25.0, Attributes: attributes},
+		metric.Event{Name: "swap /dev/sda3 priority", Value: -2.0, Attributes: attributes},
+	}
+
+	c, err := memstat.NewSwapCollector(reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	got, err := c.Collect()
+	if err != nil {
+		t.Errorf("unexpected error: %v\n", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v\n", want, got)
+	}
+}
+
+func TestHugepageCollectorCollect(t *testing.T) {
+	reader := new(memstatfakes.FakeHugepageStatReader)
+	reader.ReadStatsReturns([]memstat.HugepageStat{
+		memstat.HugepageStat{PageSizeKB: 2048, Total: 1024, Free: 256, Reserved: 128, Surplus: 1},
+	}, nil)
+
+	attributes := map[string]string{"page_size": "2048kB"}
+	want := []metric.Event{
+		metric.Event{Name: "hugepages 2048kB total", Value: 1024.0, Attributes: attributes},
+		metric.Event{Name: "hugepages 2048kB free", Value: 256.0, Attributes: attributes},
+		metric.Event{Name: "hugepages 2048kB reserved", Value: 128.0, Attributes: attributes},
+		metric.Event{Name: "hugepages 2048kB surplus", Value: 1.0, Attributes: attributes},
+	}
+
+	c, err := memstat.NewHugepageCollector(reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	got, err := c.Collect()
+	if err != nil {
+		t.Errorf("unexpected error: %v\n", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v\n", want, got)
+	}
+}
+
+func TestNodeCollectorCollect(t *testing.T) {
+	stats := [][]memstat.NodeStat{
+		[]memstat.NodeStat{memstat.NodeStat{Node: 1, MemFreeKB: 2, NumaMiss: 10}},
+		[]memstat.NodeStat{memstat.NodeStat{Node: 1, MemFreeKB: 1, NumaMiss: 20}},
+	}
+	reader := new(memstatfakes.FakeNodeStatReader)
+	i := 0
+	reader.ReadStatsStub = func() ([]memstat.NodeStat, error) {
+		ret := stats[i]
+		i++
+		return ret, nil
+	}
+
+	c, err := memstat.NewNodeCollector(reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	got, err := c.Collect()
+	if err != nil {
+		t.Errorf("unexpected error: %v\n", err)
+	}
+
+	if len(got) != 11 {
+		t.Fatalf("want 11 events, got %d\n", len(got))
+	}
+	want := metric.Event{
+		Name:       "numa node1 memory free(bytes)",
+		Value:      1024.0,
+		Attributes: map[string]string{"node": "node1"},
+	}
+	if !reflect.DeepEqual(got[1], want) {
+		t.Errorf("want %v, got %v\n", want, got[1])
+	}
+	if got[6].Name != "numa node1 miss" || got[6].Value.(float64) <= 0 {
+		t.Errorf("want positive numa miss rate, got %v\n", got[6])
+	}
+	if got[5].Name != "numa node1 hit" || got[5].Value.(float64) != 0 {
+		t.Errorf("want zero numa hit rate, got %v\n", got[5])
+	}
+}
@@ -0,0 +1,108 @@
+package memstat
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Bo0mer/yamt/internal"
+	"github.com/Bo0mer/yamt/internal/hostfs"
+)
+
+//go:generate counterfeiter . HugepageStatReader
+
+// HugepageStat represents usage of hugepages of a single size.
+type HugepageStat struct {
+	// Page size in kilobytes.
+	PageSizeKB uint64
+	// Number of pages in the pool.
+	Total uint64
+	// Number of pages not yet allocated.
+	Free uint64
+	// Number of pages reserved for allocation, but not yet allocated.
+	Reserved uint64
+	// Number of pages allocated above Total, as allowed by overcommit.
+	Surplus uint64
+}
+
+// HugepageStatReader should read usage of hugepages of all sizes.
+type HugepageStatReader interface {
+	ReadStats() ([]HugepageStat, error)
+}
+
+// HugepagesReader reads usage of hugepages.
+type HugepagesReader struct {
+	dir string
+}
+
+// NewHugepagesReader creates HugepagesReader that reads from the specified
+// directory. Paths under /sys are resolved relative to hostfs.SysRoot.
+func NewHugepagesReader(dir string) *HugepagesReader {
+	return &HugepagesReader{
+		dir: dir,
+	}
+}
+
+// DefaultHugepagesReader is the default implementation of HugepageStatReader.
+// It reads hugepage usage from /sys/kernel/mm/hugepages.
+var DefaultHugepagesReader HugepageStatReader = NewHugepagesReader("/sys/kernel/mm/hugepages")
+
+// ReadHugepageStats is shorthand for DefaultHugepagesReader.ReadStats.
+func ReadHugepageStats() ([]HugepageStat, error) {
+	return DefaultHugepagesReader.ReadStats()
+}
+
+// ReadStats reads usage of hugepages of all sizes, ordered by page size.
+func (r *HugepagesReader) ReadStats() ([]HugepageStat, error) {
+	dir := hostfs.Resolve(r.dir)
+	dirs, err := filepath.Glob(filepath.Join(dir, "hugepages-*kB"))
+	if err != nil {
+		return nil, fmt.Errorf("readhugepages: error listing %s: %v", dir, err)
+	}
+
+	stats := make([]HugepageStat, 0, len(dirs))
+	for _, d := range dirs {
+		stat, err := r.readPool(d)
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, stat)
+	}
+	sort.Sort(byPageSize(stats))
+	return stats, nil
+}
+
+// byPageSize sorts hugepage pools by page size.
+type byPageSize []HugepageStat
+
+func (s byPageSize) Len() int           { return len(s) }
+func (s byPageSize) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byPageSize) Less(i, j int) bool { return s[i].PageSizeKB < s[j].PageSizeKB }
+
+func (r *HugepagesReader) readPool(dir string) (HugepageStat, error) {
+	values := make(map[string]string)
+	for _, name := range []string{"nr_hugepages", "free_hugepages", "resv_hugepages", "surplus_hugepages"} {
+		path := filepath.Join(dir, name)
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return HugepageStat{}, fmt.Errorf("readhugepages: error reading from %s: %v", path, err)
+		}
+		values[name] = strings.TrimSpace(string(data))
+	}
+
+	size := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(dir), "hugepages-"), "kB")
+	p := &internal.ErrParser{}
+	stat := HugepageStat{
+		PageSizeKB: p.ParseUint64(size),
+		Total:      p.ParseUint64(values["nr_hugepages"]),
+		Free:       p.ParseUint64(values["free_hugepages"]),
+		Reserved:   p.ParseUint64(values["resv_hugepages"]),
+		Surplus:    p.ParseUint64(values["surplus_hugepages"]),
+	}
+	if err := p.Err(); err != nil {
+		return HugepageStat{}, fmt.Errorf("readhugepages: error parsing %s: %v", dir, err)
+	}
+	return stat, nil
+}
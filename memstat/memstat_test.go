@@ -0,0 +1,82 @@
+package memstat_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Bo0mer/yamt/memstat"
+)
+
+func TestSwapsReader(t *testing.T) {
+	r := memstat.NewSwapsReader("testdata/procSwaps")
+	got, err := r.ReadStats()
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+
+	want := []memstat.SwapStat{
+		memstat.SwapStat{Filename: "/dev/sda3", Type: "partition", SizeKB: 8388604, UsedKB: 1048576, Priority: -2},
+		memstat.SwapStat{Filename: "/swap file", Type: "file", SizeKB: 2097148, Priority: -3},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v\n", want, got)
+	}
+}
+
+func TestHugepagesReader(t *testing.T) {
+	r := memstat.NewHugepagesReader("testdata/hugepages")
+	got, err := r.ReadStats()
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+
+	want := []memstat.HugepageStat{
+		memstat.HugepageStat{PageSizeKB: 2048, Total: 1024, Free: 256, Reserved: 128},
+		memstat.HugepageStat{PageSizeKB: 1048576, Total: 4, Free: 4},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v\n", want, got)
+	}
+}
+
+func TestSysfsNodeReader(t *testing.T) {
+	r := memstat.NewSysfsNodeReader("testdata/node")
+	got, err := r.ReadStats()
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+
+	want := []memstat.NodeStat{
+		memstat.NodeStat{
+			Node:           0,
+			MemTotalKB:     32860000,
+			MemFreeKB:      10240000,
+			MemUsedKB:      22620000,
+			HugePagesTotal: 512,
+			HugePagesFree:  120,
+			NumaHit:        938726610,
+			NumaMiss:       12340,
+			NumaForeign:    5670,
+			InterleaveHit:  20340,
+			LocalNode:      938715000,
+			OtherNode:      12450,
+		},
+		memstat.NodeStat{
+			Node:           1,
+			MemTotalKB:     32860001,
+			MemFreeKB:      10240001,
+			MemUsedKB:      22620001,
+			HugePagesTotal: 512,
+			HugePagesFree:  121,
+			NumaHit:        938726611,
+			NumaMiss:       12341,
+			NumaForeign:    5671,
+			InterleaveHit:  20341,
+			LocalNode:      938715001,
+			OtherNode:      12451,
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %#v, got %#v\n", want, got)
+	}
+}
@@ -0,0 +1,155 @@
+package memstat
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Bo0mer/yamt/internal"
+	"github.com/Bo0mer/yamt/internal/hostfs"
+)
+
+//go:generate counterfeiter . NodeStatReader
+
+// NodeStat represents memory usage and allocation statistics of a single
+// NUMA node.
+type NodeStat struct {
+	// Node number.
+	Node int
+
+	// Total memory in kilobytes.
+	MemTotalKB uint64
+	// Free memory in kilobytes.
+	MemFreeKB uint64
+	// Used memory in kilobytes.
+	MemUsedKB uint64
+	// Number of hugepages of the default size on the node.
+	HugePagesTotal uint64
+	// Number of free hugepages of the default size on the node.
+	HugePagesFree uint64
+
+	// Pages allocated on this node as intended.
+	NumaHit uint64
+	// Pages allocated on this node although intended for another one.
+	NumaMiss uint64
+	// Pages intended for this node but allocated on another one.
+	NumaForeign uint64
+	// Interleaved pages allocated on this node as intended.
+	InterleaveHit uint64
+	// Pages allocated on this node while the process ran on it.
+	LocalNode uint64
+	// Pages allocated on this node while the process ran on another one.
+	OtherNode uint64
+}
+
+// NodeStatReader should read statistics of all NUMA nodes.
+type NodeStatReader interface {
+	ReadStats() ([]NodeStat, error)
+}
+
+// SysfsNodeReader reads statistics of NUMA nodes.
+type SysfsNodeReader struct {
+	dir string
+}
+
+// NewSysfsNodeReader creates SysfsNodeReader that reads from the specified
+// directory. Paths under /sys are resolved relative to hostfs.SysRoot.
+func NewSysfsNodeReader(dir string) *SysfsNodeReader {
+	return &SysfsNodeReader{
+		dir: dir,
+	}
+}
+
+// DefaultSysfsNodeReader is the default implementation of NodeStatReader.
+// It reads NUMA node statistics from /sys/devices/system/node.
+var DefaultSysfsNodeReader NodeStatReader = NewSysfsNodeReader("/sys/devices/system/node")
+
+// ReadNodeStats is shorthand for DefaultSysfsNodeReader.ReadStats.
+func ReadNodeStats() ([]NodeStat, error) {
+	return DefaultSysfsNodeReader.ReadStats()
+}
+
+// ReadStats reads statistics of all NUMA nodes, ordered by node number.
+func (r *SysfsNodeReader) ReadStats() ([]NodeStat, error) {
+	dir := hostfs.Resolve(r.dir)
+	dirs, err := filepath.Glob(filepath.Join(dir, "node[0-9]*"))
+	if err != nil {
+		return nil, fmt.Errorf("readnodes: error listing %s: %v", dir, err)
+	}
+
+	stats := make([]NodeStat, 0, len(dirs))
+	for _, d := range dirs {
+		node, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(d), "node"))
+		if err != nil {
+			continue
+		}
+		stat, err := r.readNode(d)
+		if err != nil {
+			return nil, err
+		}
+		stat.Node = node
+		stats = append(stats, stat)
+	}
+	sort.Sort(byNode(stats))
+	return stats, nil
+}
+
+// byNode sorts NUMA node statistics by node number.
+type byNode []NodeStat
+
+func (s byNode) Len() int           { return len(s) }
+func (s byNode) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byNode) Less(i, j int) bool { return s[i].Node < s[j].Node }
+
+func (r *SysfsNodeReader) readNode(dir string) (NodeStat, error) {
+	// Lines in meminfo are in "Node 0 MemFree: 1024 kB" format.
+	meminfo, err := readKeyValues(filepath.Join(dir, "meminfo"), 2)
+	if err != nil {
+		return NodeStat{}, err
+	}
+	numastat, err := readKeyValues(filepath.Join(dir, "numastat"), 0)
+	if err != nil {
+		return NodeStat{}, err
+	}
+
+	p := &internal.ErrParser{}
+	stat := NodeStat{
+		MemTotalKB:     p.ParseUint64(meminfo["MemTotal"]),
+		MemFreeKB:      p.ParseUint64(meminfo["MemFree"]),
+		MemUsedKB:      p.ParseUint64(meminfo["MemUsed"]),
+		HugePagesTotal: p.ParseUint64(meminfo["HugePages_Total"]),
+		HugePagesFree:  p.ParseUint64(meminfo["HugePages_Free"]),
+
+		NumaHit:       p.ParseUint64(numastat["numa_hit"]),
+		NumaMiss:      p.ParseUint64(numastat["numa_miss"]),
+		NumaForeign:   p.ParseUint64(numastat["numa_foreign"]),
+		InterleaveHit: p.ParseUint64(numastat["interleave_hit"]),
+		LocalNode:     p.ParseUint64(numastat["local_node"]),
+		OtherNode:     p.ParseUint64(numastat["other_node"]),
+	}
+	if err := p.Err(); err != nil {
+		return NodeStat{}, fmt.Errorf("readnodes: error parsing %s: %v", dir, err)
+	}
+	return stat, nil
+}
+
+// readKeyValues reads lines of key and value pairs, ignoring skip leading
+// fields and trailing units. Trailing colons are stripped from keys.
+func readKeyValues(path string, skip int) (map[string]string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("readnodes: error reading from %s: %v", path, err)
+	}
+	values := make(map[string]string)
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < skip+2 {
+			continue
+		}
+		values[strings.TrimSuffix(fields[skip], ":")] = fields[skip+1]
+	}
+	return values, nil
+}
@@ -0,0 +1,102 @@
+package memstat
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Bo0mer/yamt/internal"
+	"github.com/Bo0mer/yamt/metric"
+)
+
+// NodeCollector computes metrics for NUMA nodes.
+type NodeCollector struct {
+	reader   NodeStatReader
+	last     map[int]NodeStat
+	lastTime time.Time
+}
+
+// NewNodeCollector returns brand new NUMA node collector.
+func NewNodeCollector(reader NodeStatReader) (*NodeCollector, error) {
+	c := &NodeCollector{
+		reader: reader,
+	}
+	if err := c.init(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Collect collects stats and creates events for NUMA nodes. Memory usage is
+// reported as is, allocation counters as rates.
+func (c *NodeCollector) Collect() ([]metric.Event, error) {
+	stats, actual, err := c.getState()
+	if err != nil {
+		return nil, err
+	}
+
+	actualTime := time.Now()
+	interval := actualTime.Sub(c.lastTime).Seconds()
+
+	events := make([]metric.Event, 0)
+	for _, stat := range stats {
+		last, ok := c.last[stat.Node]
+		if !ok {
+			continue
+		}
+		events = append(events, c.buildEvents(stat, last, interval)...)
+	}
+
+	c.last = actual
+	c.lastTime = actualTime
+
+	return events, nil
+}
+
+// init loads the initial state of the collector.
+func (c *NodeCollector) init() error {
+	_, state, err := c.getState()
+	if err != nil {
+		return err
+	}
+	c.last = state
+	c.lastTime = time.Now()
+	return nil
+}
+
+// getState reads current state for all NUMA nodes. Stats are returned in
+// the order they were read along with the state keyed by node.
+func (c *NodeCollector) getState() ([]NodeStat, map[int]NodeStat, error) {
+	stats, err := c.reader.ReadStats()
+	if err != nil {
+		return nil, nil, fmt.Errorf("collector: error reading stats: %v", err)
+	}
+	state := make(map[int]NodeStat)
+	for _, stat := range stats {
+		state[stat.Node] = stat
+	}
+	return stats, state, nil
+}
+
+// buildEvents builds all events for a single NUMA node.
+func (c *NodeCollector) buildEvents(actual, last NodeStat, interval float64) []metric.Event {
+	node := "node" + strconv.Itoa(actual.Node)
+	event := eventBuilder("numa "+node, map[string]string{"node": node})
+	rate := internal.RateComputer(interval)
+
+	events := make([]metric.Event, 0)
+	events = append(events, event("memory total(bytes)", float64(actual.MemTotalKB*1024)))
+	events = append(events, event("memory free(bytes)", float64(actual.MemFreeKB*1024)))
+	events = append(events, event("memory used(bytes)", float64(actual.MemUsedKB*1024)))
+	events = append(events, event("hugepages total", float64(actual.HugePagesTotal)))
+	events = append(events, event("hugepages free", float64(actual.HugePagesFree)))
+
+	events = append(events, event("hit", rate(actual.NumaHit, last.NumaHit)))
+	events = append(events, event("miss", rate(actual.NumaMiss, last.NumaMiss)))
+	events = append(events, event("foreign", rate(actual.NumaForeign, last.NumaForeign)))
+	events = append(events, event("interleave hit", rate(actual.InterleaveHit, last.InterleaveHit)))
+	events = append(events, event("local node", rate(actual.LocalNode, last.LocalNode)))
+	events = append(events, event("other node", rate(actual.OtherNode, last.OtherNode)))
+
+	return events
+}
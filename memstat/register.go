@@ -0,0 +1,42 @@
+package memstat
+
+import (
+	"github.com/Bo0mer/yamt/metric"
+	"github.com/Bo0mer/yamt/registry"
+)
+
+func init() {
+	registry.Register(registry.Collector{
+		Name:        "swap",
+		Description: "Report swap device usage",
+		New: func(c registry.Config) ([]metric.Collector, error) {
+			sc, err := NewSwapCollector(DefaultSwapsReader)
+			if err != nil {
+				return nil, err
+			}
+			return []metric.Collector{metric.Named("swap", sc)}, nil
+		},
+	})
+	registry.Register(registry.Collector{
+		Name:        "hugepages",
+		Description: "Report hugepage usage",
+		New: func(c registry.Config) ([]metric.Collector, error) {
+			hc, err := NewHugepageCollector(DefaultHugepagesReader)
+			if err != nil {
+				return nil, err
+			}
+			return []metric.Collector{metric.Named("hugepages", hc)}, nil
+		},
+	})
+	registry.Register(registry.Collector{
+		Name:        "numa",
+		Description: "Report per NUMA node memory usage and allocation metrics",
+		New: func(c registry.Config) ([]metric.Collector, error) {
+			nc, err := NewNodeCollector(DefaultSysfsNodeReader)
+			if err != nil {
+				return nil, err
+			}
+			return []metric.Collector{metric.Named("numa", nc)}, nil
+		},
+	})
+}
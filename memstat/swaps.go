@@ -0,0 +1,88 @@
+package memstat
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/Bo0mer/yamt/internal"
+	"github.com/Bo0mer/yamt/internal/hostfs"
+)
+
+//go:generate counterfeiter . SwapStatReader
+
+// SwapStat represents usage of a swap device or file.
+type SwapStat struct {
+	// Path of the swap device or file.
+	Filename string
+	// Swap type, partition or file.
+	Type string
+	// Size in kilobytes.
+	SizeKB uint64
+	// Used space in kilobytes.
+	UsedKB uint64
+	// Devices with higher priority are used first.
+	Priority int
+}
+
+// SwapStatReader should read usage of all swap devices.
+type SwapStatReader interface {
+	ReadStats() ([]SwapStat, error)
+}
+
+// SwapsReader reads usage of swap devices.
+type SwapsReader struct {
+	path string
+}
+
+// NewSwapsReader creates SwapsReader that reads from the specified path.
+// Paths under /proc are resolved relative to hostfs.ProcRoot.
+func NewSwapsReader(path string) *SwapsReader {
+	return &SwapsReader{
+		path: path,
+	}
+}
+
+// DefaultSwapsReader is the default implementation of SwapStatReader.
+// It reads swap usage from /proc/swaps.
+var DefaultSwapsReader SwapStatReader = NewSwapsReader("/proc/swaps")
+
+// ReadSwapStats is shorthand for DefaultSwapsReader.ReadStats.
+func ReadSwapStats() ([]SwapStat, error) {
+	return DefaultSwapsReader.ReadStats()
+}
+
+// ReadStats reads usage of all swap devices.
+func (r *SwapsReader) ReadStats() ([]SwapStat, error) {
+	path := hostfs.Resolve(r.path)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("readswaps: error reading from %s: %v", path, err)
+	}
+	return r.parseStats(data)
+}
+
+func (r *SwapsReader) parseStats(data []byte) ([]SwapStat, error) {
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	stats := make([]SwapStat, 0)
+	for i, line := range lines[1:] { // skip header
+		fields := strings.Fields(line)
+		if len(fields) < 5 {
+			return nil, fmt.Errorf("readswaps: unsupported format on line %d: %q", i+1, line)
+		}
+		p := &internal.ErrParser{}
+		stat := SwapStat{
+			// Whitespace in file names is octal escaped.
+			Filename: strings.Replace(fields[0], `\040`, " ", -1),
+			Type:     fields[1],
+			SizeKB:   p.ParseUint64(fields[2]),
+			UsedKB:   p.ParseUint64(fields[3]),
+			Priority: p.ParseInt(fields[4]),
+		}
+		if err := p.Err(); err != nil {
+			return nil, fmt.Errorf("readswaps: error parsing line %d: %v", i+1, err)
+		}
+		stats = append(stats, stat)
+	}
+	return stats, nil
+}
@@ -0,0 +1,211 @@
+package metric
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Statistic is a statistic computed over the samples of a window.
+type Statistic string
+
+// Supported statistics.
+const (
+	Min    Statistic = "min"
+	Max    Statistic = "max"
+	Mean   Statistic = "mean"
+	P50    Statistic = "p50"
+	P95    Statistic = "p95"
+	P99    Statistic = "p99"
+	StdDev Statistic = "stddev"
+)
+
+var statistics = []Statistic{Min, Max, Mean, P50, P95, P99, StdDev}
+
+// ParseStatistics parses comma separated list of statistics, e.g.
+// "min,max,p95".
+func ParseStatistics(s string) ([]Statistic, error) {
+	stats := make([]Statistic, 0)
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		known := false
+		for _, stat := range statistics {
+			if Statistic(name) == stat {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("metric: unknown statistic %q", name)
+		}
+		stats = append(stats, Statistic(name))
+	}
+	if len(stats) == 0 {
+		return nil, fmt.Errorf("metric: no statistics in %q", s)
+	}
+	return stats, nil
+}
+
+// Sampled returns collector which collects c every sample interval, and
+// reports the specified statistics over the samples gathered since it was
+// last collected. Each statistic is reported as a separate event, named
+// after the original one and the statistic, e.g. "sda reads total p95", with
+// a statistic attribute. Sampling is driven by the reporter and starts with
+// it.
+func Sampled(c Collector, every time.Duration, stats ...Statistic) Collector {
+	return &aggregator{collector: c, every: every, stats: stats}
+}
+
+// sampler is a collector sampled by the reporter more often than it is
+// collected.
+type sampler interface {
+	Collector
+	sample(ctx context.Context) error
+	sampleInterval() time.Duration
+}
+
+// series holds the samples of a single event within a window.
+type series struct {
+	last   Event
+	values []float64
+}
+
+type aggregator struct {
+	collector Collector
+	every     time.Duration
+	stats     []Statistic
+
+	mu     sync.Mutex
+	window map[string]*series
+	// order keeps events in the order they were first sampled.
+	order []string
+}
+
+func (a *aggregator) sampleInterval() time.Duration {
+	return a.every
+}
+
+// sample collects events from the underlying collector and adds them to the
+// current window. Events with non numeric values are ignored.
+func (a *aggregator) sample(ctx context.Context) error {
+	events, err := collect(ctx, a.collector)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.window == nil {
+		a.window = make(map[string]*series)
+	}
+	for _, event := range events {
+		value, ok := toFloat(event.Value)
+		if !ok {
+			continue
+		}
+		s, ok := a.window[event.Name]
+		if !ok {
+			s = &series{}
+			a.window[event.Name] = s
+			a.order = append(a.order, event.Name)
+		}
+		s.last = event
+		s.values = append(s.values, value)
+	}
+	return nil
+}
+
+// Collect reports statistics of the current window and starts a new one.
+// Events which were not sampled since the last call are not reported.
+func (a *aggregator) Collect() ([]Event, error) {
+	a.mu.Lock()
+	window, order := a.window, a.order
+	a.window, a.order = nil, nil
+	a.mu.Unlock()
+
+	events := make([]Event, 0)
+	for _, name := range order {
+		s := window[name]
+		sort.Float64s(s.values)
+		for _, stat := range a.stats {
+			attributes := make(map[string]string, len(s.last.Attributes)+1)
+			for k, v := range s.last.Attributes {
+				attributes[k] = v
+			}
+			attributes["statistic"] = string(stat)
+			events = append(events, Event{
+				Name:       name + " " + string(stat),
+				Value:      compute(stat, s.values),
+				State:      s.last.State,
+				Attributes: attributes,
+			})
+		}
+	}
+	return events, nil
+}
+
+// compute computes the statistic over sorted values.
+func compute(stat Statistic, values []float64) float64 {
+	switch stat {
+	case Min:
+		return values[0]
+	case Max:
+		return values[len(values)-1]
+	case Mean:
+		return mean(values)
+	case P50:
+		return percentile(values, 50)
+	case P95:
+		return percentile(values, 95)
+	case P99:
+		return percentile(values, 99)
+	case StdDev:
+		m := mean(values)
+		var sum float64
+		for _, v := range values {
+			sum += (v - m) * (v - m)
+		}
+		return math.Sqrt(sum / float64(len(values)))
+	}
+	return math.NaN()
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// percentile returns the nearest-rank percentile of sorted values.
+func percentile(values []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(values))))
+	if rank < 1 {
+		rank = 1
+	}
+	return values[rank-1]
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	}
+	return 0, false
+}
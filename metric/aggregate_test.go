@@ -0,0 +1,72 @@
+package metric
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// sequence returns event "x" with the next value on each call.
+type sequence struct {
+	values []float64
+}
+
+func (s *sequence) Collect() ([]Event, error) {
+	v := s.values[0]
+	s.values = s.values[1:]
+	return []Event{Event{Name: "x", Value: v, Attributes: map[string]string{"device": "sda"}}}, nil
+}
+
+func TestParseStatistics(t *testing.T) {
+	stats, err := ParseStatistics("min, max,p95")
+	if err != nil {
+		t.Fatalf("expected no error, got %v\n", err)
+	}
+	if want := []Statistic{Min, Max, P95}; !reflect.DeepEqual(stats, want) {
+		t.Errorf("expected %v, got %v\n", want, stats)
+	}
+	for _, s := range []string{"", "min,p42"} {
+		if _, err := ParseStatistics(s); err == nil {
+			t.Errorf("expected error parsing %q\n", s)
+		}
+	}
+}
+
+func TestSampled(t *testing.T) {
+	values := []float64{4, 2, 8, 6, 10, 1, 3, 5, 9, 7}
+	c := Sampled(&sequence{values: values}, 0, Min, Max, Mean, P50, P95, StdDev).(*aggregator)
+	for range values {
+		if err := c.sample(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v\n", err)
+		}
+	}
+
+	events, err := c.Collect()
+	if err != nil {
+		t.Fatalf("expected no error, got %v\n", err)
+	}
+	want := map[string]float64{
+		"x min":    1,
+		"x max":    10,
+		"x mean":   5.5,
+		"x p50":    5,
+		"x p95":    10,
+		"x stddev": 2.8722813232690143,
+	}
+	if len(events) != len(want) {
+		t.Fatalf("expected %d events, got %v\n", len(want), events)
+	}
+	for _, e := range events {
+		if e.Value != want[e.Name] {
+			t.Errorf("expected %s to be %v, got %v\n", e.Name, want[e.Name], e.Value)
+		}
+		if e.Attributes["device"] != "sda" || e.Attributes["statistic"] == "" {
+			t.Errorf("expected device and statistic attributes, got %v\n", e.Attributes)
+		}
+	}
+
+	events, _ = c.Collect()
+	if len(events) != 0 {
+		t.Errorf("expected new window to be empty, got %v\n", events)
+	}
+}
@@ -0,0 +1,60 @@
+package metric
+
+import (
+	"context"
+	"fmt"
+)
+
+// ContextCollector is a Collector which can abandon collection when the
+// context is done, e.g. when the reporter deadline for it expires.
+type ContextCollector interface {
+	Collector
+	CollectContext(ctx context.Context) ([]Event, error)
+}
+
+// Named returns collector which reports under the specified name, e.g. in
+// logs and timeout events. Collectors which are not named are reported by
+// their type.
+func Named(name string, c Collector) Collector {
+	return &namedCollector{name: name, Collector: c}
+}
+
+type namedCollector struct {
+	Collector
+	name string
+}
+
+func (c *namedCollector) CollectContext(ctx context.Context) ([]Event, error) {
+	if cc, ok := c.Collector.(ContextCollector); ok {
+		return cc.CollectContext(ctx)
+	}
+	return c.Collect()
+}
+
+// unwrap returns the collector given to Named, or c if it is not named.
+func unwrap(c Collector) Collector {
+	if n, ok := c.(*namedCollector); ok {
+		return n.Collector
+	}
+	return c
+}
+
+// collectorName returns name of the collector given to Named, or its type.
+// Sampled collectors are reported by the name of the sampled one.
+func collectorName(c Collector) string {
+	switch c := c.(type) {
+	case *namedCollector:
+		return c.name
+	case *aggregator:
+		return collectorName(c.collector)
+	}
+	return fmt.Sprintf("%T", c)
+}
+
+// collect collects events from c, passing ctx to context aware collectors.
+func collect(ctx context.Context, c Collector) ([]Event, error) {
+	if cc, ok := c.(ContextCollector); ok {
+		return cc.CollectContext(ctx)
+	}
+	return c.Collect()
+}
@@ -1,18 +1,41 @@
 package metric
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 //go:generate counterfeiter . Collector
 //go:generate counterfeiter . Emitter
 
+// Event states understood by emitters.
+const (
+	StateOK       = "ok"
+	StateWarning  = "warning"
+	StateCritical = "critical"
+	StateUnknown  = "unknown"
+	// StateExpired marks events of entities which are gone, e.g. removed
+	// devices, telling backends to forget them.
+	StateExpired = "expired"
+)
+
 // Event repesents generic metric event.
 type Event struct {
 	Name string
 	// could be int, float32 or float64
 	Value interface{}
+	// State of the measured entity, e.g. StateCritical. Empty state is
+	// treated as StateOK by emitters.
+	State string
+	// Attributes describe the event, e.g. the device or cgroup it is
+	// about. Emitters may use them as labels.
+	Attributes map[string]string
 }
 
 // Collector collects metric events.
@@ -26,6 +49,12 @@ type Emitter interface {
 	Emit(Event) error
 }
 
+// Closer is an Emitter holding resources, e.g. connections, which are
+// released by the reporter on Close.
+type Closer interface {
+	Close() error
+}
+
 type Option func(*Reporter)
 
 func Interval(d time.Duration) Option {
@@ -34,64 +63,326 @@ func Interval(d time.Duration) Option {
 	}
 }
 
+// Timeout sets the deadline for a single collection. Collections which
+// miss it are reported by a critical timeout event and their events are
+// dropped. Defaults to the interval of the collector.
+func Timeout(d time.Duration) Option {
+	return func(r *Reporter) {
+		r.timeout = d
+	}
+}
+
+// ShutdownTimeout sets the deadline for Close to wait for running
+// collections and emits. Defaults to 5 seconds.
+func ShutdownTimeout(d time.Duration) Option {
+	return func(r *Reporter) {
+		r.shutdownTimeout = d
+	}
+}
+
+// Schedule registers collector to be collected every interval instead of
+// the reporter interval. The first collection happens offset plus interval
+// after Start, or offset after an aligned tick with Align, which allows
+// spreading collections over time.
+func Schedule(c Collector, interval, offset time.Duration) Option {
+	return func(r *Reporter) {
+		r.schedules = append(r.schedules, schedule{
+			collectors: []Collector{c},
+			interval:   interval,
+			offset:     offset,
+		})
+	}
+}
+
+// schedule is a group of collectors collected together.
+type schedule struct {
+	collectors []Collector
+	interval   time.Duration
+	offset     time.Duration
+}
+
+// job tracks a single collector, so that overlapping collections of the
+// same collector are skipped.
+type job struct {
+	collector Collector
+	name      string
+	// running is 1 while a collection is in progress.
+	running int32
+}
+
 // Reporter periodically collects and emits metrics.
 type Reporter struct {
 	emitter    Emitter
+	processor  Processor
 	collectors []Collector
+	schedules  []schedule
+
+	interval        time.Duration
+	align           bool
+	splay           time.Duration
+	timeout         time.Duration
+	shutdownTimeout time.Duration
+	stop            chan struct{}
+
+	// ctx is cancelled when Close gives up waiting for collections.
+	ctx    context.Context
+	cancel context.CancelFunc
+	// loops tracks schedule goroutines and collections tracks running
+	// collections, so that Close can wait for them.
+	loops       sync.WaitGroup
+	collections sync.WaitGroup
+	closeOnce   sync.Once
+	closeErr    error
+
+	// emitSem serializes emits from concurrent collections. It is a
+	// semaphore rather than a mutex, so that Close can give up waiting for
+	// a hung emit. Events are dropped once closed is set.
+	emitSem chan struct{}
+	closed  int32
 
-	interval time.Duration
-	stop     chan struct{}
+	stats      selfStats
+	self       bool
+	selfPrefix string
 }
 
-// NewReporter returns brand new reporter.
+// NewReporter returns brand new reporter. The specified collectors are
+// collected every Interval, see Schedule for collectors with their own
+// interval.
 func NewReporter(e Emitter, collectors []Collector, opts ...Option) *Reporter {
 	r := &Reporter{
-		emitter:    e,
-		collectors: collectors,
-		interval:   time.Second,
-		stop:       make(chan struct{}),
+		emitter:         e,
+		collectors:      collectors,
+		interval:        time.Second,
+		shutdownTimeout: 5 * time.Second,
+		stop:            make(chan struct{}),
+		emitSem:         make(chan struct{}, 1),
 	}
 	for _, opt := range opts {
 		opt(r)
 	}
+	r.ctx, r.cancel = context.WithCancel(context.Background())
 	return r
 }
 
 // Start starts collecting and emitting metric events.
-// It does so in its own goroutine. See Close for stopping.
+// Each schedule runs in its own goroutine and collectors run concurrently.
+// See Close for stopping.
 func (r *Reporter) Start() {
-	go r.start()
+	schedules := r.schedules
+	if len(r.collectors) > 0 {
+		schedules = append(schedules, schedule{collectors: r.collectors, interval: r.interval})
+	}
+	if r.self {
+		self := &selfCollector{prefix: r.selfPrefix, stats: &r.stats}
+		schedules = append(schedules, schedule{collectors: []Collector{Named("self", self)}, interval: r.interval})
+	}
+	r.loops.Add(len(schedules))
+	for _, s := range schedules {
+		go r.start(s)
+	}
+}
+
+func (r *Reporter) start(s schedule) {
+	defer r.loops.Done()
+	jobs := make([]*job, len(s.collectors))
+	for i, c := range s.collectors {
+		jobs[i] = &job{collector: c, name: collectorName(c)}
+	}
+	timeout := r.timeout
+	if timeout <= 0 {
+		timeout = s.interval
+	}
+
+	for _, j := range jobs {
+		if smp, ok := unwrap(j.collector).(sampler); ok {
+			r.loops.Add(1)
+			go r.sample(j.name, smp)
+		}
+	}
+
+	tick := firstTick(time.Now(), s.interval, s.offset+r.splay, r.align)
+	t := time.NewTimer(tick.Sub(time.Now()))
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			now := time.Now()
+			r.stats.recordLag(now.Sub(tick))
+			tick = nextTick(tick, now, s.interval)
+			t.Reset(tick.Sub(time.Now()))
+			for _, j := range jobs {
+				if !atomic.CompareAndSwapInt32(&j.running, 0, 1) {
+					log.Printf("reporter: skipping collection of %s, previous one still running\n", j.name)
+					continue
+				}
+				r.collections.Add(1)
+				go r.collectAndEmit(j, timeout)
+			}
+		case <-r.stop:
+			return
+		}
+	}
 }
 
-func (r *Reporter) start() {
-	t := time.NewTicker(r.interval)
+// sample samples the collector until the reporter is closed.
+func (r *Reporter) sample(name string, s sampler) {
+	defer r.loops.Done()
+	t := time.NewTicker(s.sampleInterval())
+	defer t.Stop()
 	for {
 		select {
 		case <-t.C:
-			for _, c := range r.collectors {
-				r.collectAndEmit(c)
+			ctx, cancel := context.WithTimeout(r.ctx, s.sampleInterval())
+			if err := s.sample(ctx); err != nil {
+				log.Printf("reporter: error sampling metrics from %s: %v\n", name, err)
 			}
+			cancel()
 		case <-r.stop:
 			return
 		}
 	}
 }
 
-func (r *Reporter) collectAndEmit(c Collector) {
-	events, err := c.Collect()
-	if err != nil {
-		log.Printf("reporter: error collecting metrics: %v\n", err)
+type collectResult struct {
+	events []Event
+	err    error
+}
+
+// collectAndEmit collects events from the job collector and emits them,
+// unless the collection misses its deadline. The job is marked as not
+// running only when the collector returns, even if that is after the
+// deadline.
+func (r *Reporter) collectAndEmit(j *job, timeout time.Duration) {
+	defer r.collections.Done()
+	ctx, cancel := context.WithTimeout(r.ctx, timeout)
+	defer cancel()
+
+	done := make(chan collectResult, 1)
+	go func() {
+		defer atomic.StoreInt32(&j.running, 0)
+		start := time.Now()
+		events, err := collect(ctx, j.collector)
+		r.stats.recordCollection(j, time.Since(start), len(events), err)
+		done <- collectResult{events: events, err: err}
+	}()
+
+	var res collectResult
+	select {
+	case res = <-done:
+	case <-ctx.Done():
+		if r.ctx.Err() != nil {
+			log.Printf("reporter: abandoning collection of %s due to shutdown\n", j.name)
+			return
+		}
+		log.Printf("reporter: collection of %s timed out after %v\n", j.name, timeout)
+		r.stats.recordTimeout(j)
+		r.emit([]Event{timeoutEvent(j.name)})
 		return
 	}
+	if res.err != nil {
+		log.Printf("reporter: error collecting metrics from %s: %v\n", j.name, res.err)
+		return
+	}
+	r.emit(res.events)
+}
+
+func (r *Reporter) emit(events []Event) {
+	r.emitSem <- struct{}{}
+	defer func() { <-r.emitSem }()
 	for _, event := range events {
-		if err := r.emitter.Emit(event); err != nil {
+		if atomic.LoadInt32(&r.closed) != 0 {
+			return
+		}
+		if r.processor != nil {
+			var ok bool
+			if event, ok = r.processor.Process(event); !ok {
+				continue
+			}
+		}
+		start := time.Now()
+		err := r.emitter.Emit(event)
+		r.stats.recordEmit(time.Since(start), err)
+		if err != nil {
 			log.Printf("reporter: error emitting metric: %v\n", err)
 			continue
 		}
 	}
 }
 
-// Close releases all resources allocated by the reporter.
-func (r *Reporter) Close() {
+// timeoutEvent returns event reporting that collection timed out.
+func timeoutEvent(name string) Event {
+	return Event{
+		Name:       "collector " + name + " timeout",
+		Value:      1.0,
+		State:      StateCritical,
+		Attributes: map[string]string{"collector": name},
+	}
+}
+
+// Close stops scheduling collections and waits for the running ones to
+// emit their events. Then it closes the emitter, if it is a Closer.
+// Collections still running after the ShutdownTimeout are cancelled and
+// their events dropped. An emitter still busy emitting by then is left
+// open. Errors from the emitter are returned, subsequent calls return the
+// same error.
+func (r *Reporter) Close() error {
+	r.closeOnce.Do(func() {
+		r.closeErr = r.close()
+	})
+	return r.closeErr
+}
+
+func (r *Reporter) close() error {
 	close(r.stop)
+	r.loops.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.shutdownTimeout)
+	defer cancel()
+
+	var errs []string
+	done := make(chan struct{})
+	go func() {
+		r.collections.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		r.cancel()
+		errs = append(errs, "reporter: timed out waiting for running collections")
+	}
+
+	atomic.StoreInt32(&r.closed, 1)
+	r.cancel()
+	if r.lockEmit(ctx) {
+		if c, ok := r.emitter.(Closer); ok {
+			if err := c.Close(); err != nil {
+				errs = append(errs, fmt.Sprintf("reporter: error closing emitter: %v", err))
+			}
+		}
+		<-r.emitSem
+	} else {
+		// Emitters are not safe for concurrent use, so a hung one can
+		// not be closed.
+		errs = append(errs, "reporter: timed out waiting for running emits")
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// lockEmit acquires emitSem, giving up once ctx is done.
+func (r *Reporter) lockEmit(ctx context.Context) bool {
+	select {
+	case r.emitSem <- struct{}{}:
+		return true
+	default:
+	}
+	select {
+	case r.emitSem <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
 }
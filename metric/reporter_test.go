@@ -1,6 +1,10 @@
 package metric_test
 
 import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -39,10 +43,16 @@ func TestReporter(t *testing.T) {
 			return
 		default:
 			if emitter.EmitCallCount() >= 2 {
-				if got1 := emitter.EmitArgsForCall(0); got1 != want1 {
+				// Collectors run concurrently, so events may come in any
+				// order.
+				got1, got2 := emitter.EmitArgsForCall(0), emitter.EmitArgsForCall(1)
+				if got1.Name == want2.Name {
+					got1, got2 = got2, got1
+				}
+				if !reflect.DeepEqual(got1, want1) {
 					t.Errorf("expected call to emitter with %v, got %v\n", want1, got1)
 				}
-				if got2 := emitter.EmitArgsForCall(1); got2 != want2 {
+				if !reflect.DeepEqual(got2, want2) {
 					t.Errorf("expected call to emitter with %v, got %v\n", want2, got2)
 				}
 				return
@@ -51,3 +61,325 @@ func TestReporter(t *testing.T) {
 		}
 	}
 }
+
+func TestReporter_schedule(t *testing.T) {
+	emitter := new(metricfakes.FakeEmitter)
+	fast, slow := new(metricfakes.FakeCollector), new(metricfakes.FakeCollector)
+	fast.CollectReturns([]metric.Event{metric.Event{Name: "fast"}}, nil)
+	slow.CollectReturns([]metric.Event{metric.Event{Name: "slow"}}, nil)
+
+	r := metric.NewReporter(emitter, []metric.Collector{slow},
+		metric.Interval(time.Hour),
+		metric.Schedule(fast, 10*time.Millisecond, 5*time.Millisecond))
+
+	r.Start()
+	time.Sleep(100 * time.Millisecond)
+	r.Close()
+
+	if n := fast.CollectCallCount(); n < 3 {
+		t.Errorf("expected fast collector to be called at least 3 times, got %d\n", n)
+	}
+	if n := slow.CollectCallCount(); n != 0 {
+		t.Errorf("expected slow collector not to be called, got %d calls\n", n)
+	}
+	for i := 0; i < emitter.EmitCallCount(); i++ {
+		if got := emitter.EmitArgsForCall(i); got.Name != "fast" {
+			t.Errorf("expected only fast events, got %v\n", got)
+		}
+	}
+}
+
+func TestReporter_timeout(t *testing.T) {
+	emitter := new(metricfakes.FakeEmitter)
+	slow := new(metricfakes.FakeCollector)
+	release := make(chan struct{})
+	slow.CollectStub = func() ([]metric.Event, error) {
+		<-release
+		return []metric.Event{metric.Event{Name: "late"}}, nil
+	}
+	fast := new(metricfakes.FakeCollector)
+	fast.CollectReturns([]metric.Event{metric.Event{Name: "fast"}}, nil)
+
+	r := metric.NewReporter(emitter, []metric.Collector{metric.Named("slow", slow), fast},
+		metric.Interval(20*time.Millisecond),
+		metric.Timeout(5*time.Millisecond))
+	r.Start()
+	time.Sleep(110 * time.Millisecond)
+	r.Close()
+	close(release)
+
+	// The slow collector blocks, so its later runs overlap and are skipped.
+	if n := slow.CollectCallCount(); n != 1 {
+		t.Errorf("expected one call to slow collector, got %d\n", n)
+	}
+	if n := fast.CollectCallCount(); n < 3 {
+		t.Errorf("expected fast collector not to be blocked, got %d calls\n", n)
+	}
+
+	want := metric.Event{
+		Name:       "collector slow timeout",
+		Value:      1.0,
+		State:      metric.StateCritical,
+		Attributes: map[string]string{"collector": "slow"},
+	}
+	timeouts := 0
+	for i := 0; i < emitter.EmitCallCount(); i++ {
+		got := emitter.EmitArgsForCall(i)
+		switch got.Name {
+		case "late":
+			t.Errorf("expected events of timed out collection to be dropped\n")
+		case want.Name:
+			timeouts++
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("expected %v, got %v\n", want, got)
+			}
+		}
+	}
+	if timeouts != 1 {
+		t.Errorf("expected one timeout event, got %d\n", timeouts)
+	}
+}
+
+type contextCollector struct {
+	deadline chan bool
+}
+
+func (c *contextCollector) Collect() ([]metric.Event, error) {
+	return nil, nil
+}
+
+func (c *contextCollector) CollectContext(ctx context.Context) ([]metric.Event, error) {
+	_, ok := ctx.Deadline()
+	c.deadline <- ok
+	return nil, nil
+}
+
+func TestReporter_contextCollector(t *testing.T) {
+	c := &contextCollector{deadline: make(chan bool, 10)}
+	r := metric.NewReporter(new(metricfakes.FakeEmitter), []metric.Collector{metric.Named("ctx", c)},
+		metric.Interval(5*time.Millisecond))
+	r.Start()
+	defer r.Close()
+
+	select {
+	case ok := <-c.deadline:
+		if !ok {
+			t.Error("expected context with deadline")
+		}
+	case <-time.After(time.Second):
+		t.Error("expected CollectContext to be called")
+	}
+}
+
+func TestReporter_selfMetrics(t *testing.T) {
+	emitter := new(metricfakes.FakeEmitter)
+	c := new(metricfakes.FakeCollector)
+	c.CollectReturns([]metric.Event{metric.Event{Name: "a"}, metric.Event{Name: "b"}}, nil)
+	broken := new(metricfakes.FakeCollector)
+	broken.CollectReturns(nil, errors.New("kaboom"))
+
+	r := metric.NewReporter(emitter, []metric.Collector{metric.Named("c", c), metric.Named("broken", broken)},
+		metric.Interval(10*time.Millisecond),
+		metric.SelfMetrics("yamt."))
+	r.Start()
+	time.Sleep(100 * time.Millisecond)
+	r.Close()
+
+	got := make(map[string]metric.Event)
+	var brokenErrors float64
+	for i := 0; i < emitter.EmitCallCount(); i++ {
+		e := emitter.EmitArgsForCall(i)
+		got[e.Name] = e
+		if e.Name == "yamt.collector broken errors" {
+			brokenErrors += e.Value.(float64)
+		}
+	}
+
+	want := metric.Event{Name: "yamt.collector c events", Value: 2.0, Attributes: map[string]string{"collector": "c"}}
+	if !reflect.DeepEqual(got[want.Name], want) {
+		t.Errorf("expected %v, got %v\n", want, got[want.Name])
+	}
+	if brokenErrors < 1 {
+		t.Errorf("expected errors of broken collector to be reported, got %v\n", brokenErrors)
+	}
+	for _, name := range []string{
+		"yamt.collector c duration(ms)",
+		"yamt.emitter events",
+		"yamt.emitter errors",
+		"yamt.emitter latency(ms)",
+		"yamt.schedule lag(ms)",
+		"yamt.process goroutines",
+		"yamt.process gc pause(ms)",
+	} {
+		if _, ok := got[name]; !ok {
+			t.Errorf("expected %q event to be emitted\n", name)
+		}
+	}
+}
+
+// closingEmitter is an emitter which is also a Closer.
+type closingEmitter struct {
+	*metricfakes.FakeEmitter
+	closed   bool
+	closeErr error
+}
+
+func (e *closingEmitter) Close() error {
+	e.closed = true
+	return e.closeErr
+}
+
+func TestReporter_close(t *testing.T) {
+	emitter := &closingEmitter{FakeEmitter: new(metricfakes.FakeEmitter)}
+	c := new(metricfakes.FakeCollector)
+	c.CollectStub = func() ([]metric.Event, error) {
+		time.Sleep(30 * time.Millisecond)
+		return []metric.Event{metric.Event{Name: "c"}}, nil
+	}
+
+	r := metric.NewReporter(emitter, []metric.Collector{c},
+		metric.Interval(10*time.Millisecond),
+		metric.ShutdownTimeout(time.Second))
+	r.Start()
+	for c.CollectCallCount() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("expected no error, got %v\n", err)
+	}
+
+	if n := emitter.EmitCallCount(); n != 1 {
+		t.Errorf("expected events of the running collection to be emitted, got %d emits\n", n)
+	}
+	if !emitter.closed {
+		t.Errorf("expected emitter to be closed\n")
+	}
+	n := c.CollectCallCount()
+	time.Sleep(30 * time.Millisecond)
+	if c.CollectCallCount() != n {
+		t.Errorf("expected no collections after close\n")
+	}
+}
+
+func TestReporter_closeTimeout(t *testing.T) {
+	emitter := &closingEmitter{
+		FakeEmitter: new(metricfakes.FakeEmitter),
+		closeErr:    errors.New("kaboom"),
+	}
+	c := new(metricfakes.FakeCollector)
+	c.CollectStub = func() ([]metric.Event, error) {
+		time.Sleep(100 * time.Millisecond)
+		return []metric.Event{metric.Event{Name: "c"}}, nil
+	}
+
+	r := metric.NewReporter(emitter, []metric.Collector{c},
+		metric.Interval(10*time.Millisecond),
+		metric.Timeout(time.Hour),
+		metric.ShutdownTimeout(10*time.Millisecond))
+	r.Start()
+	time.Sleep(20 * time.Millisecond)
+
+	err := r.Close()
+	if err == nil || !strings.Contains(err.Error(), "timed out") || !strings.Contains(err.Error(), "kaboom") {
+		t.Errorf("expected timeout and close errors, got %v\n", err)
+	}
+	if !emitter.closed {
+		t.Errorf("expected emitter to be closed\n")
+	}
+	if n := emitter.EmitCallCount(); n != 0 {
+		t.Errorf("expected events of abandoned collection to be dropped, got %d emits\n", n)
+	}
+	if r.Close() != err {
+		t.Errorf("expected subsequent close to return the same error\n")
+	}
+	time.Sleep(100 * time.Millisecond)
+	if n := emitter.EmitCallCount(); n != 0 {
+		t.Errorf("expected no emits after close, got %d\n", n)
+	}
+}
+
+func TestReporter_closeHungEmit(t *testing.T) {
+	emitter := &closingEmitter{FakeEmitter: new(metricfakes.FakeEmitter)}
+	hung := make(chan struct{})
+	defer close(hung)
+	emitter.EmitStub = func(metric.Event) error {
+		<-hung
+		return nil
+	}
+	c := new(metricfakes.FakeCollector)
+	c.CollectReturns([]metric.Event{metric.Event{Name: "c"}, metric.Event{Name: "d"}}, nil)
+
+	r := metric.NewReporter(emitter, []metric.Collector{c},
+		metric.Interval(10*time.Millisecond),
+		metric.ShutdownTimeout(20*time.Millisecond))
+	r.Start()
+	for emitter.EmitCallCount() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- r.Close()
+	}()
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "emits") {
+			t.Errorf("expected emit timeout error, got %v\n", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected close not to wait for hung emit")
+	}
+	if emitter.closed {
+		t.Errorf("expected hung emitter not to be closed\n")
+	}
+}
+
+func TestReporter_sampled(t *testing.T) {
+	emitter := new(metricfakes.FakeEmitter)
+	c := new(metricfakes.FakeCollector)
+	c.CollectReturns([]metric.Event{metric.Event{Name: "c", Value: 1.0}}, nil)
+
+	r := metric.NewReporter(emitter, []metric.Collector{metric.Named("c", metric.Sampled(c, 5*time.Millisecond, metric.Max))},
+		metric.Interval(50*time.Millisecond))
+	r.Start()
+	time.Sleep(80 * time.Millisecond)
+	r.Close()
+
+	if n := c.CollectCallCount(); n < 5 {
+		t.Errorf("expected collector to be sampled at least 5 times, got %d\n", n)
+	}
+	want := metric.Event{Name: "c max", Value: 1.0, Attributes: map[string]string{"statistic": "max"}}
+	if n := emitter.EmitCallCount(); n != 1 {
+		t.Fatalf("expected one emit per interval, got %d\n", n)
+	}
+	if got := emitter.EmitArgsForCall(0); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v\n", want, got)
+	}
+}
+
+func TestReporter_align(t *testing.T) {
+	interval := 50 * time.Millisecond
+	ticks := make(chan time.Time, 10)
+	c := new(metricfakes.FakeCollector)
+	c.CollectStub = func() ([]metric.Event, error) {
+		ticks <- time.Now()
+		return nil, nil
+	}
+
+	r := metric.NewReporter(new(metricfakes.FakeEmitter), []metric.Collector{c},
+		metric.Interval(interval),
+		metric.Align())
+	r.Start()
+	defer r.Close()
+
+	for i := 0; i < 3; i++ {
+		select {
+		case tick := <-ticks:
+			if d := tick.Sub(tick.Truncate(interval)); d > 20*time.Millisecond {
+				t.Errorf("expected collection aligned to the interval, got %v past it\n", d)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("expected collector to be called")
+		}
+	}
+}
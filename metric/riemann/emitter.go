@@ -73,9 +73,10 @@ func (e *Emitter) Emit(event metric.Event) error {
 		Service:    prependPrefix(event.Name, e.prefix),
 		Metric:     event.Value,
 		Host:       e.host,
-		Attributes: e.attributes,
+		Attributes: mergeAttributes(e.attributes, event.Attributes),
 		Tags:       e.tags,
-		State:      "ok",
+		State:      state(event.State),
+		Ttl:        ttl(event.State),
 	})
 	if err != nil {
 		e.c.Close()
@@ -84,6 +85,52 @@ func (e *Emitter) Emit(event metric.Event) error {
 	return err
 }
 
+// Close closes the connection to Riemann, if any. Emit reconnects.
+func (e *Emitter) Close() error {
+	if !e.isConnected {
+		return nil
+	}
+	e.isConnected = false
+	return e.c.Close()
+}
+
+// state returns the Riemann state of an event with the specified state.
+func state(s string) string {
+	if s == "" {
+		return metric.StateOK
+	}
+	return s
+}
+
+// expiredTTL is the time to live of expired events, so that Riemann
+// removes them right away. Zero TTL would not be sent at all.
+const expiredTTL = 0.001
+
+// ttl returns time to live of an event with the specified state, zero for
+// the Riemann default.
+func ttl(s string) float32 {
+	if s == metric.StateExpired {
+		return expiredTTL
+	}
+	return 0
+}
+
+// mergeAttributes returns union of the emitter and event attributes, the
+// latter taking precedence.
+func mergeAttributes(attributes, eventAttributes map[string]string) map[string]string {
+	if len(eventAttributes) == 0 {
+		return attributes
+	}
+	merged := make(map[string]string, len(attributes)+len(eventAttributes))
+	for k, v := range attributes {
+		merged[k] = v
+	}
+	for k, v := range eventAttributes {
+		merged[k] = v
+	}
+	return merged
+}
+
 func prependPrefix(service string, prefix string) string {
 	if prefix == "" {
 		return service
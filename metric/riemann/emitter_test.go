@@ -1,6 +1,10 @@
 package riemann
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/Bo0mer/yamt/metric"
+)
 
 func TestPrefix(t *testing.T) {
 	prefix := "woho"
@@ -56,3 +60,46 @@ func TestPrependPrefix(t *testing.T) {
 		t.Errorf("expected 'service', got: %q\n", got)
 	}
 }
+
+func TestMergeAttributes(t *testing.T) {
+	attr := map[string]string{"dc": "eu", "role": "db"}
+	eventAttr := map[string]string{"role": "web", "cgroup": "/"}
+	got := mergeAttributes(attr, eventAttr)
+	want := map[string]string{"dc": "eu", "role": "web", "cgroup": "/"}
+	if len(got) != len(want) {
+		t.Errorf("expected attributes %v, got %v\n", want, got)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("expected attributes %v, got %v\n", want, got)
+		}
+	}
+	if attr["role"] != "db" {
+		t.Errorf("expected emitter attributes to be left intact, got %v\n", attr)
+	}
+}
+
+func TestState(t *testing.T) {
+	if got := state(""); got != "ok" {
+		t.Errorf("expected 'ok', got %q\n", got)
+	}
+	if got := state("critical"); got != "critical" {
+		t.Errorf("expected 'critical', got %q\n", got)
+	}
+}
+
+func TestCloseNotConnected(t *testing.T) {
+	e := NewEmitter("")
+	if err := e.Close(); err != nil {
+		t.Errorf("expected no error, got %v\n", err)
+	}
+}
+
+func TestTTL(t *testing.T) {
+	if got := ttl(metric.StateOK); got != 0 {
+		t.Errorf("expected default ttl, got %v\n", got)
+	}
+	if got := ttl(metric.StateExpired); got <= 0 {
+		t.Errorf("expected positive ttl of expired events, got %v\n", got)
+	}
+}
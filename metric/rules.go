@@ -0,0 +1,219 @@
+package metric
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+)
+
+// Processor processes events before they are emitted.
+type Processor interface {
+	// Process returns the processed event, or false if the event should be
+	// dropped.
+	Process(Event) (Event, bool)
+}
+
+// Process sets processor applied to each event before it is emitted.
+func Process(p Processor) Option {
+	return func(r *Reporter) {
+		r.processor = p
+	}
+}
+
+// Action is what a rule does with events it matches.
+type Action string
+
+// Supported actions.
+const (
+	// Drop drops matching events.
+	Drop Action = "drop"
+	// Keep drops events which do not match.
+	Keep Action = "keep"
+	// Rename replaces the name of matching events with Replacement, which
+	// may refer to capture groups of Name, e.g. "${1} reads".
+	Rename Action = "rename"
+	// Set sets Label of matching events to Replacement, which may refer
+	// to capture groups of Name.
+	Set Action = "set"
+	// Remove removes Label from matching events.
+	Remove Action = "remove"
+	// Scale multiplies value of matching events by Factor.
+	Scale Action = "scale"
+)
+
+// Rule processes events matching both its Name and Labels.
+type Rule struct {
+	Action Action
+	// Name must match the whole event name, if set.
+	Name *regexp.Regexp
+	// Labels must match the whole values of the respective event
+	// attributes, missing attributes match as empty.
+	Labels map[string]*regexp.Regexp
+
+	Replacement string
+	Label       string
+	Factor      float64
+}
+
+// Rules is a Processor applying rules in order. Processing stops once an
+// event is dropped.
+type Rules []Rule
+
+// Process applies the rules to the event.
+func (rules Rules) Process(e Event) (Event, bool) {
+	for _, rule := range rules {
+		match, ok := rule.match(e)
+		if rule.Action == Keep {
+			if !ok {
+				return Event{}, false
+			}
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		switch rule.Action {
+		case Drop:
+			return Event{}, false
+		case Rename:
+			e.Name = rule.expand(e.Name, match)
+		case Set:
+			e.Attributes = copyAttributes(e.Attributes)
+			e.Attributes[rule.Label] = rule.expand(e.Name, match)
+		case Remove:
+			if _, ok := e.Attributes[rule.Label]; ok {
+				e.Attributes = copyAttributes(e.Attributes)
+				delete(e.Attributes, rule.Label)
+			}
+		case Scale:
+			if v, ok := toFloat(e.Value); ok {
+				e.Value = v * rule.Factor
+			}
+		}
+	}
+	return e, true
+}
+
+// match tells whether the event matches the rule, returning submatch
+// indices of the name.
+func (rule Rule) match(e Event) ([]int, bool) {
+	var match []int
+	if rule.Name != nil {
+		if match = rule.Name.FindStringSubmatchIndex(e.Name); match == nil {
+			return nil, false
+		}
+	}
+	for label, re := range rule.Labels {
+		if !re.MatchString(e.Attributes[label]) {
+			return nil, false
+		}
+	}
+	return match, true
+}
+
+func (rule Rule) expand(name string, match []int) string {
+	if rule.Name == nil {
+		return rule.Replacement
+	}
+	return string(rule.Name.ExpandString(nil, rule.Replacement, name, match))
+}
+
+// copyAttributes returns a copy of attributes, which may be shared between
+// events.
+func copyAttributes(attributes map[string]string) map[string]string {
+	c := make(map[string]string, len(attributes)+1)
+	for k, v := range attributes {
+		c[k] = v
+	}
+	return c
+}
+
+// ruleConfig is the JSON representation of a rule.
+type ruleConfig struct {
+	Action      Action            `json:"action"`
+	Name        string            `json:"name"`
+	Labels      map[string]string `json:"labels"`
+	Replacement string            `json:"replacement"`
+	Label       string            `json:"label"`
+	Factor      float64           `json:"factor"`
+}
+
+// ParseRules parses JSON array of rules, e.g.
+//
+//	[
+//	  {"action": "drop", "name": ".* (rx compressed|tx fifo)"},
+//	  {"action": "rename", "name": "(.*) reads total", "replacement": "${1} reads"},
+//	  {"action": "set", "labels": {"device": "nvme.*"}, "label": "tier", "replacement": "fast"},
+//	  {"action": "scale", "name": ".*\\(ms\\)", "factor": 0.001}
+//	]
+func ParseRules(r io.Reader) (Rules, error) {
+	var configs []ruleConfig
+	if err := json.NewDecoder(r).Decode(&configs); err != nil {
+		return nil, fmt.Errorf("metric: error decoding rules: %v", err)
+	}
+
+	rules := make(Rules, 0, len(configs))
+	for i, config := range configs {
+		rule, err := compileRule(config)
+		if err != nil {
+			return nil, fmt.Errorf("metric: invalid rule %d: %v", i, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func compileRule(config ruleConfig) (Rule, error) {
+	rule := Rule{
+		Action:      config.Action,
+		Replacement: config.Replacement,
+		Label:       config.Label,
+		Factor:      config.Factor,
+	}
+	switch config.Action {
+	case Drop, Keep:
+	case Rename:
+		// Renaming all events to the same name would make them collide.
+		if config.Name == "" {
+			return Rule{}, fmt.Errorf("%s requires name", config.Action)
+		}
+		if config.Replacement == "" {
+			return Rule{}, fmt.Errorf("%s requires replacement", config.Action)
+		}
+	case Set, Remove:
+		if config.Label == "" {
+			return Rule{}, fmt.Errorf("%s requires label", config.Action)
+		}
+	case Scale:
+		if config.Factor == 0 {
+			return Rule{}, fmt.Errorf("%s requires factor", config.Action)
+		}
+	default:
+		return Rule{}, fmt.Errorf("unknown action %q", config.Action)
+	}
+
+	var err error
+	if config.Name != "" {
+		if rule.Name, err = anchored(config.Name); err != nil {
+			return Rule{}, err
+		}
+	}
+	for label, expr := range config.Labels {
+		re, err := anchored(expr)
+		if err != nil {
+			return Rule{}, err
+		}
+		if rule.Labels == nil {
+			rule.Labels = make(map[string]*regexp.Regexp)
+		}
+		rule.Labels[label] = re
+	}
+	return rule, nil
+}
+
+// anchored compiles regexp matching whole strings.
+func anchored(expr string) (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:" + expr + ")$")
+}
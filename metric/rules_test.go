@@ -0,0 +1,109 @@
+package metric_test
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Bo0mer/yamt/metric"
+	"github.com/Bo0mer/yamt/metric/metricfakes"
+)
+
+const rules = `[
+	{"action": "drop", "name": ".* (rx compressed|tx fifo)"},
+	{"action": "keep", "labels": {"device": "sd.*|eth0"}},
+	{"action": "rename", "name": "(\\w+) reads total", "replacement": "${1} reads"},
+	{"action": "set", "name": "(\\w+) .*", "label": "disk", "replacement": "${1}"},
+	{"action": "set", "labels": {"device": "sdb"}, "label": "tier", "replacement": "slow"},
+	{"action": "remove", "label": "size"},
+	{"action": "scale", "name": ".*\\(ms\\)", "factor": 0.001}
+]`
+
+func TestRules(t *testing.T) {
+	r, err := metric.ParseRules(strings.NewReader(rules))
+	if err != nil {
+		t.Fatalf("expected no error, got %v\n", err)
+	}
+
+	sda := map[string]string{"device": "sda", "size": "42"}
+	tests := []struct {
+		in   metric.Event
+		want metric.Event
+		ok   bool
+	}{
+		{
+			in: metric.Event{Name: "eth0 rx compressed", Value: 1.0, Attributes: map[string]string{"device": "eth0"}},
+		},
+		{
+			in: metric.Event{Name: "lo rx bytes", Value: 1.0, Attributes: map[string]string{"device": "lo"}},
+		},
+		{
+			in:   metric.Event{Name: "sda reads total", Value: 2.0, Attributes: sda},
+			want: metric.Event{Name: "sda reads", Value: 2.0, Attributes: map[string]string{"device": "sda", "disk": "sda"}},
+			ok:   true,
+		},
+		{
+			in:   metric.Event{Name: "sdb io time(ms)", Value: 500.0, Attributes: map[string]string{"device": "sdb"}},
+			want: metric.Event{Name: "sdb io time(ms)", Value: 0.5, Attributes: map[string]string{"device": "sdb", "disk": "sdb", "tier": "slow"}},
+			ok:   true,
+		},
+	}
+	for _, test := range tests {
+		got, ok := r.Process(test.in)
+		if ok != test.ok {
+			t.Errorf("%s: expected %v, got %v\n", test.in.Name, test.ok, ok)
+			continue
+		}
+		if ok && !reflect.DeepEqual(got, test.want) {
+			t.Errorf("expected %v, got %v\n", test.want, got)
+		}
+	}
+	if want := map[string]string{"device": "sda", "size": "42"}; !reflect.DeepEqual(sda, want) {
+		t.Errorf("expected attributes of the original event to stay intact, got %v\n", sda)
+	}
+}
+
+func TestParseRules_invalid(t *testing.T) {
+	for _, s := range []string{
+		`{}`,
+		`[{"action": "explode"}]`,
+		`[{"action": "rename", "name": "x"}]`,
+		`[{"action": "rename", "replacement": "x"}]`,
+		`[{"action": "rename", "labels": {"device": "sda"}, "replacement": "x"}]`,
+		`[{"action": "set", "replacement": "x"}]`,
+		`[{"action": "remove"}]`,
+		`[{"action": "scale"}]`,
+		`[{"action": "drop", "name": "("}]`,
+		`[{"action": "drop", "labels": {"device": "("}}]`,
+	} {
+		if _, err := metric.ParseRules(strings.NewReader(s)); err == nil {
+			t.Errorf("expected error parsing %s\n", s)
+		}
+	}
+}
+
+func TestReporter_process(t *testing.T) {
+	emitter := new(metricfakes.FakeEmitter)
+	c := new(metricfakes.FakeCollector)
+	c.CollectReturns([]metric.Event{metric.Event{Name: "noise"}, metric.Event{Name: "signal"}}, nil)
+
+	r := metric.NewReporter(emitter, []metric.Collector{c},
+		metric.Interval(10*time.Millisecond),
+		metric.Process(metric.Rules{{Action: metric.Drop, Name: regexp.MustCompile("noise")}}))
+	r.Start()
+	for c.CollectCallCount() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	r.Close()
+
+	for i := 0; i < emitter.EmitCallCount(); i++ {
+		if e := emitter.EmitArgsForCall(i); e.Name != "signal" {
+			t.Errorf("expected only signal to be emitted, got %v\n", e)
+		}
+	}
+	if emitter.EmitCallCount() == 0 {
+		t.Errorf("expected signal to be emitted\n")
+	}
+}
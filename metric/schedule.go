@@ -0,0 +1,63 @@
+package metric
+
+import (
+	"hash/fnv"
+	"time"
+)
+
+// Align aligns collections to wall-clock multiples of their interval, e.g.
+// at :00, :10, :20 seconds of each minute for 10 second interval, so that
+// reports of different hosts line up. Schedule offsets and Splay shift the
+// alignment.
+func Align() Option {
+	return func(r *Reporter) {
+		r.align = true
+	}
+}
+
+// Splay delays collections by a deterministic duration less than max,
+// derived from key, e.g. the hostname. It spreads reports of many hosts
+// over time, while each host keeps reporting at the same offset.
+func Splay(max time.Duration, key string) Option {
+	return func(r *Reporter) {
+		r.splay = splay(max, key)
+	}
+}
+
+// splay returns duration less than max derived from key.
+func splay(max time.Duration, key string) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return time.Duration(h.Sum64() % uint64(max))
+}
+
+// firstTick returns when the first collection of a schedule with the
+// specified interval and offset started at now should happen.
+func firstTick(now time.Time, interval, offset time.Duration, align bool) time.Time {
+	if !align {
+		return now.Add(offset + interval)
+	}
+	// Truncate strips the monotonic clock reading, so that the ticks
+	// follow the wall clock.
+	tick := now.Truncate(interval).Add(offset % interval)
+	for !tick.After(now) {
+		tick = tick.Add(interval)
+	}
+	return tick
+}
+
+// nextTick returns the first tick after now of the schedule with the
+// specified interval, which last ticked at last. Ticks missed due to slow
+// collections or clock changes are skipped, so that the schedule does not
+// drift.
+func nextTick(last, now time.Time, interval time.Duration) time.Time {
+	next := last.Add(interval)
+	if next.After(now) {
+		return next
+	}
+	missed := now.Sub(next)/interval + 1
+	return next.Add(missed * interval)
+}
@@ -0,0 +1,63 @@
+package metric
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSplay(t *testing.T) {
+	a, b := splay(time.Minute, "host-a"), splay(time.Minute, "host-b")
+	if a != splay(time.Minute, "host-a") {
+		t.Error("expected splay to be deterministic")
+	}
+	if a == b {
+		t.Errorf("expected hosts to be splayed differently, got %v\n", a)
+	}
+	for _, d := range []time.Duration{a, b} {
+		if d < 0 || d >= time.Minute {
+			t.Errorf("expected splay within a minute, got %v\n", d)
+		}
+	}
+	if d := splay(0, "host-a"); d != 0 {
+		t.Errorf("expected no splay, got %v\n", d)
+	}
+}
+
+func TestFirstTick(t *testing.T) {
+	now := time.Date(2017, 3, 1, 12, 0, 7, 0, time.UTC)
+	cases := []struct {
+		offset time.Duration
+		align  bool
+		want   time.Time
+	}{
+		{0, false, now.Add(10 * time.Second)},
+		{2 * time.Second, false, now.Add(12 * time.Second)},
+		{0, true, time.Date(2017, 3, 1, 12, 0, 10, 0, time.UTC)},
+		{2 * time.Second, true, time.Date(2017, 3, 1, 12, 0, 12, 0, time.UTC)},
+		{8 * time.Second, true, time.Date(2017, 3, 1, 12, 0, 8, 0, time.UTC)},
+		{13 * time.Second, true, time.Date(2017, 3, 1, 12, 0, 13, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		if got := firstTick(now, 10*time.Second, c.offset, c.align); !got.Equal(c.want) {
+			t.Errorf("offset %v, align %v: expected %v, got %v\n", c.offset, c.align, c.want, got)
+		}
+	}
+}
+
+func TestNextTick(t *testing.T) {
+	last := time.Date(2017, 3, 1, 12, 0, 10, 0, time.UTC)
+	cases := []struct {
+		now  time.Time
+		want time.Time
+	}{
+		{last.Add(time.Millisecond), last.Add(10 * time.Second)},
+		// Ticks missed due to a slow collection or clock change are skipped.
+		{last.Add(10 * time.Second), last.Add(20 * time.Second)},
+		{last.Add(35 * time.Second), last.Add(40 * time.Second)},
+	}
+	for _, c := range cases {
+		if got := nextTick(last, c.now, 10*time.Second); !got.Equal(c.want) {
+			t.Errorf("now %v: expected %v, got %v\n", c.now, c.want, got)
+		}
+	}
+}
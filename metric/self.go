@@ -0,0 +1,176 @@
+package metric
+
+import (
+	"io/ioutil"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SelfMetrics makes the reporter emit metrics about itself every Interval,
+// with names starting with prefix, e.g. "yamt.". They include duration,
+// event and error counts of each collector, emit counts and latency,
+// schedule lag, and memory, goroutine and garbage collection stats of the
+// process.
+func SelfMetrics(prefix string) Option {
+	return func(r *Reporter) {
+		r.selfPrefix = prefix
+		r.self = true
+	}
+}
+
+// collectorStats tracks collections of a single collector since the last
+// self report.
+type collectorStats struct {
+	name     string
+	duration time.Duration
+	events   int
+	errors   int
+	timeouts int
+}
+
+// selfStats tracks reporter activity since the last self report.
+type selfStats struct {
+	mu         sync.Mutex
+	collectors map[*job]*collectorStats
+	// order keeps collectors in the order they were first seen, so that
+	// events are reported in stable order.
+	order []*job
+
+	emitted    int
+	emitErrors int
+	emitTime   time.Duration
+	lag        time.Duration
+}
+
+func (s *selfStats) collector(j *job) *collectorStats {
+	if s.collectors == nil {
+		s.collectors = make(map[*job]*collectorStats)
+	}
+	cs, ok := s.collectors[j]
+	if !ok {
+		cs = &collectorStats{name: j.name}
+		s.collectors[j] = cs
+		s.order = append(s.order, j)
+	}
+	return cs
+}
+
+func (s *selfStats) recordCollection(j *job, d time.Duration, events int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cs := s.collector(j)
+	cs.duration = d
+	cs.events = events
+	if err != nil {
+		cs.errors++
+	}
+}
+
+func (s *selfStats) recordTimeout(j *job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.collector(j).timeouts++
+}
+
+func (s *selfStats) recordEmit(d time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.emitTime += d
+	if err != nil {
+		s.emitErrors++
+	} else {
+		s.emitted++
+	}
+}
+
+func (s *selfStats) recordLag(lag time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if lag > s.lag {
+		s.lag = lag
+	}
+}
+
+// selfCollector reports reporter and process stats.
+type selfCollector struct {
+	prefix string
+	stats  *selfStats
+
+	lastNumGC     uint32
+	lastPauseTime uint64
+}
+
+// Collect creates events from the stats gathered since the last call and
+// resets them.
+func (c *selfCollector) Collect() ([]Event, error) {
+	events := make([]Event, 0)
+	event := func(name string, value float64, attributes map[string]string) {
+		events = append(events, Event{
+			Name:       c.prefix + name,
+			Value:      value,
+			Attributes: attributes,
+		})
+	}
+
+	s := c.stats
+	s.mu.Lock()
+	for _, j := range s.order {
+		cs := s.collectors[j]
+		attributes := map[string]string{"collector": cs.name}
+		prefix := "collector " + cs.name + " "
+		event(prefix+"duration(ms)", milliseconds(cs.duration), attributes)
+		event(prefix+"events", float64(cs.events), attributes)
+		event(prefix+"errors", float64(cs.errors), attributes)
+		event(prefix+"timeouts", float64(cs.timeouts), attributes)
+		cs.errors, cs.timeouts = 0, 0
+	}
+
+	var latency float64
+	if n := s.emitted + s.emitErrors; n > 0 {
+		latency = milliseconds(s.emitTime) / float64(n)
+	}
+	event("emitter events", float64(s.emitted), nil)
+	event("emitter errors", float64(s.emitErrors), nil)
+	event("emitter latency(ms)", latency, nil)
+	event("schedule lag(ms)", milliseconds(s.lag), nil)
+	s.emitted, s.emitErrors, s.emitTime, s.lag = 0, 0, 0, 0
+	s.mu.Unlock()
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	if rss, ok := readRSS(); ok {
+		event("process rss(bytes)", float64(rss), nil)
+	}
+	event("process heap(bytes)", float64(mem.HeapAlloc), nil)
+	event("process goroutines", float64(runtime.NumGoroutine()), nil)
+	event("process gc count", float64(mem.NumGC-c.lastNumGC), nil)
+	event("process gc pause(ms)", float64(mem.PauseTotalNs-c.lastPauseTime)/1e6, nil)
+	c.lastNumGC, c.lastPauseTime = mem.NumGC, mem.PauseTotalNs
+
+	return events, nil
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// readRSS returns resident set size of the process in bytes.
+func readRSS() (uint64, bool) {
+	data, err := ioutil.ReadFile("/proc/self/statm")
+	if err != nil {
+		return 0, false
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) < 2 {
+		return 0, false
+	}
+	pages, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return pages * uint64(os.Getpagesize()), true
+}
@@ -3,6 +3,8 @@ package netstat
 import (
 	"fmt"
 	"regexp"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/Bo0mer/yamt/internal"
@@ -11,20 +13,72 @@ import (
 
 type state map[string]IfStat
 
+// sorted returns stats sorted by name, so that events and sums across
+// devices do not depend on the map iteration order.
+func (s state) sorted() []IfStat {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	stats := make([]IfStat, 0, len(names))
+	for _, name := range names {
+		stats = append(stats, s[name])
+	}
+	return stats
+}
+
+type Option func(*IfStatCollector)
+
+// Total makes the collector report sums of all reported interfaces, named
+// e.g. "total rx bytes".
+func Total() Option {
+	return func(c *IfStatCollector) {
+		c.groups = append(c.groups, internal.RollupGroup{Name: "total"})
+	}
+}
+
+// Group makes the collector report sums of the reported interfaces
+// matching match, named after the group, e.g. "bonds rx bytes".
+func Group(name string, match *regexp.Regexp) Option {
+	return func(c *IfStatCollector) {
+		c.groups = append(c.groups, internal.RollupGroup{Name: name, Match: match})
+	}
+}
+
+// Lifecycle makes the collector report interfaces which appeared or
+// disappeared since the last collection, and expire events of interfaces
+// gone for longer than grace, see metric.StateExpired.
+func Lifecycle(grace time.Duration) Option {
+	return func(c *IfStatCollector) {
+		c.trackLifecycle = true
+		c.grace = grace
+	}
+}
+
 // IfStatCollector computes metrics for network interfaces.
 type IfStatCollector struct {
 	reader   InterfaceStatReader
 	except   *regexp.Regexp
 	last     state
 	lastTime time.Time
+
+	groups []internal.RollupGroup
+
+	trackLifecycle bool
+	grace          time.Duration
+	lifecycle      *internal.DeviceLifecycle
 }
 
 // NewIfStatCollector returns brand new interface stats collector.
-func NewIfStatCollector(reader InterfaceStatReader, except *regexp.Regexp) (*IfStatCollector, error) {
+func NewIfStatCollector(reader InterfaceStatReader, except *regexp.Regexp, opts ...Option) (*IfStatCollector, error) {
 	c := &IfStatCollector{
 		reader: reader,
 		except: except,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
 	if err := c.init(); err != nil {
 		return nil, err
 	}
@@ -42,17 +96,31 @@ func (c *IfStatCollector) Collect() ([]metric.Event, error) {
 	interval := actualTime.Sub(c.lastTime).Seconds()
 
 	events := make([]metric.Event, 0)
+	rollup := internal.NewRollup(c.groups)
+	names := make([]string, 0, len(actual))
 
-	for _, stat := range actual {
+	for _, stat := range actual.sorted() {
 		if c.except != nil && c.except.MatchString(stat.Name) {
 			continue
 		}
+		names = append(names, stat.Name)
 		last, ok := c.last[stat.Name]
 		if !ok {
 			continue
 		}
 
-		events = append(events, c.buildEvents(stat, last, interval)...)
+		ifEvents := c.buildEvents(stat, last, interval)
+		for _, e := range ifEvents {
+			rollup.Add(stat.Name, strings.TrimPrefix(e.Name, stat.Name+" "), e.Value.(float64))
+		}
+		if c.lifecycle != nil {
+			c.lifecycle.Reported(stat.Name, ifEvents)
+		}
+		events = append(events, ifEvents...)
+	}
+	events = append(events, rollup.Events()...)
+	if c.lifecycle != nil {
+		events = append(events, c.lifecycle.Update(names, actualTime)...)
 	}
 
 	c.last = actual
@@ -69,6 +137,16 @@ func (c *IfStatCollector) init() error {
 	}
 	c.last = state
 	c.lastTime = time.Now()
+	if c.trackLifecycle {
+		// Interfaces present on start are not reported as appeared.
+		names := make([]string, 0, len(state))
+		for _, stat := range state {
+			if c.except == nil || !c.except.MatchString(stat.Name) {
+				names = append(names, stat.Name)
+			}
+		}
+		c.lifecycle = internal.NewDeviceLifecycle(c.grace, names)
+	}
 	return nil
 }
 
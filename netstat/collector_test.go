@@ -2,6 +2,7 @@ package netstat_test
 
 import (
 	"errors"
+	"reflect"
 	"regexp"
 	"testing"
 
@@ -99,7 +100,7 @@ func TestIfStatCollectorCollect(t *testing.T) {
 			}
 			continue
 		}
-		if got[i] != want[i] {
+		if !reflect.DeepEqual(got[i], want[i]) {
 			t.Errorf("expected %#v, got %#v\n", want[i], got[i])
 		}
 	}
@@ -121,3 +122,102 @@ func TestIfStatCollectorCollect_except(t *testing.T) {
 		t.Errorf("expected zero results, got %v\n", got)
 	}
 }
+
+func TestIfStatCollectorCollect_rollup(t *testing.T) {
+	reader := new(netstatfakes.FakeInterfaceStatReader)
+	i := uint64(0)
+	reader.ReadStatsStub = func() ([]netstat.IfStat, error) {
+		i++
+		return []netstat.IfStat{
+			netstat.IfStat{Name: "eth0", RxBytes: 1000 * i},
+			netstat.IfStat{Name: "bond0", RxBytes: 2000 * i},
+			netstat.IfStat{Name: "bond1", RxBytes: 3000 * i},
+		}, nil
+	}
+	c, err := netstat.NewIfStatCollector(reader, nil,
+		netstat.Total(),
+		netstat.Group("bonds", regexp.MustCompile("^bond")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+
+	got, err := c.Collect()
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	if len(got) != 5*16 {
+		t.Fatalf("expected %d events, got %d\n", 5*16, len(got))
+	}
+	events := make(map[string]metric.Event)
+	for _, e := range got {
+		events[e.Name] = e
+	}
+	value := func(name string) float64 {
+		return events[name].Value.(float64)
+	}
+
+	if want := value("bond0 rx bytes") + value("bond1 rx bytes") + value("eth0 rx bytes"); value("total rx bytes") != want {
+		t.Errorf("expected total rx bytes %f, got %f\n", want, value("total rx bytes"))
+	}
+	if want := value("bond0 rx bytes") + value("bond1 rx bytes"); value("bonds rx bytes") != want {
+		t.Errorf("expected bonds rx bytes %f, got %f\n", want, value("bonds rx bytes"))
+	}
+	if want := map[string]string{"group": "bonds"}; !reflect.DeepEqual(events["bonds rx bytes"].Attributes, want) {
+		t.Errorf("expected attributes %v, got %v\n", want, events["bonds rx bytes"].Attributes)
+	}
+}
+
+func TestIfStatCollectorCollect_lifecycle(t *testing.T) {
+	eth0 := netstat.IfStat{Name: "eth0"}
+	veth0 := netstat.IfStat{Name: "veth0"}
+	samples := [][]netstat.IfStat{
+		{eth0},
+		{eth0},
+		{eth0, veth0},
+		{eth0, veth0},
+		{eth0},
+	}
+	reader := new(netstatfakes.FakeInterfaceStatReader)
+	reader.ReadStatsStub = func() ([]netstat.IfStat, error) {
+		ret := samples[0]
+		samples = samples[1:]
+		return ret, nil
+	}
+	c, err := netstat.NewIfStatCollector(reader, nil, netstat.Lifecycle(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+
+	names := func() map[string]string {
+		got, err := c.Collect()
+		if err != nil {
+			t.Fatalf("unexpected error: %v\n", err)
+		}
+		names := make(map[string]string)
+		for _, e := range got {
+			names[e.Name] = e.State
+		}
+		return names
+	}
+
+	if got := names(); len(got) != 16 {
+		t.Errorf("expected 16 events of eth0, got %v\n", got)
+	}
+	if got := names(); len(got) != 17 {
+		t.Errorf("expected 16 events of eth0 and 1 of veth0, got %v\n", got)
+	} else if _, ok := got["veth0 appeared"]; !ok {
+		t.Errorf("expected veth0 to appear, got %v\n", got)
+	}
+	if got := names(); len(got) != 32 {
+		t.Errorf("expected 32 events of eth0 and veth0, got %v\n", got)
+	}
+	got := names()
+	for _, name := range []string{"veth0 appeared", "veth0 rx bytes", "veth0 tx compressed"} {
+		if got[name] != metric.StateExpired {
+			t.Errorf("expected %s to be expired, got %v\n", name, got)
+		}
+	}
+	if _, ok := got["veth0 disappeared"]; !ok {
+		t.Errorf("expected veth0 to disappear, got %v\n", got)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/Bo0mer/yamt/internal"
+	"github.com/Bo0mer/yamt/internal/hostfs"
 )
 
 //go:generate counterfeiter . InterfaceStatReader
@@ -45,6 +46,7 @@ type IfStatReader struct {
 }
 
 // NewIfStatReader creates IfStatReader that reads from the specified path.
+// Paths under /proc are resolved relative to hostfs.ProcRoot.
 // It expects well defined format and may cause panics if it is not present.
 func NewIfStatReader(path string) *IfStatReader {
 	return &IfStatReader{
@@ -61,10 +63,12 @@ func ReadIfStats() ([]IfStat, error) {
 	return DefaultIfStatReader.ReadStats()
 }
 
+// ReadStats reads statistics for all network interfaces.
 func (r *IfStatReader) ReadStats() ([]IfStat, error) {
-	data, err := ioutil.ReadFile(r.path)
+	path := hostfs.Resolve(r.path)
+	data, err := ioutil.ReadFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("readifstats: error reading from /proc/net/dev: %s", err)
+		return nil, fmt.Errorf("readifstats: error reading from %s: %s", path, err)
 	}
 	return r.parseStats(data)
 }
@@ -0,0 +1,158 @@
+package netstat
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Bo0mer/yamt/internal/hostfs"
+)
+
+//go:generate counterfeiter . InterruptStatReader
+
+// InterruptStat represents per CPU counts of a single interrupt.
+type InterruptStat struct {
+	// Interrupt name, e.g. 24, NMI or NET_RX.
+	Name string
+	// Devices handling the interrupt, e.g. eth0-TxRx-0. For interrupts
+	// without a device it holds the description, e.g. Non-maskable
+	// interrupts.
+	Device string
+	// Interrupt counts, indexed by CPU column.
+	PerCPU []uint64
+	// CPUs holds numbers of the CPUs of the columns, as named by the
+	// header. Offline CPUs have no column, e.g. CPU0 CPU2 CPU3.
+	CPUs []int
+}
+
+// cpu returns number of the CPU of the specified column, which is the
+// column itself if CPU numbers are unknown.
+func (s InterruptStat) cpu(column int) int {
+	if column < len(s.CPUs) {
+		return s.CPUs[column]
+	}
+	return column
+}
+
+// InterruptStatReader should read counts of all interrupts.
+type InterruptStatReader interface {
+	ReadStats() ([]InterruptStat, error)
+}
+
+// InterruptsReader reads interrupt counts. It understands the format of both
+// /proc/interrupts and /proc/softirqs.
+type InterruptsReader struct {
+	path string
+}
+
+// NewInterruptsReader creates InterruptsReader that reads from the specified
+// path. Paths under /proc are resolved relative to hostfs.ProcRoot.
+func NewInterruptsReader(path string) *InterruptsReader {
+	return &InterruptsReader{
+		path: path,
+	}
+}
+
+// DefaultInterruptsReader is the default implementation of
+// InterruptStatReader. It reads hardware interrupt counts from
+// /proc/interrupts.
+var DefaultInterruptsReader InterruptStatReader = NewInterruptsReader("/proc/interrupts")
+
+// DefaultSoftirqsReader reads software interrupt counts from /proc/softirqs.
+var DefaultSoftirqsReader InterruptStatReader = NewInterruptsReader("/proc/softirqs")
+
+// ReadInterruptStats is shorthand for DefaultInterruptsReader.ReadStats.
+func ReadInterruptStats() ([]InterruptStat, error) {
+	return DefaultInterruptsReader.ReadStats()
+}
+
+// ReadStats reads counts of all interrupts.
+func (r *InterruptsReader) ReadStats() ([]InterruptStat, error) {
+	path := hostfs.Resolve(r.path)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("readinterrupts: error reading from %s: %v", path, err)
+	}
+	return r.parseStats(data)
+}
+
+func (r *InterruptsReader) parseStats(data []byte) ([]InterruptStat, error) {
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	cpus, err := parseHeader(lines[0])
+	if err != nil {
+		return nil, fmt.Errorf("readinterrupts: error parsing header: %v", err)
+	}
+
+	stats := make([]InterruptStat, 0)
+	for i, line := range lines[1:] {
+		stat, err := r.parseLine(line, len(cpus))
+		if err != nil {
+			return nil, fmt.Errorf("readinterrupts: error parsing line %d: %v", i+1, err)
+		}
+		stat.CPUs = cpus
+		stats = append(stats, stat)
+	}
+	return stats, nil
+}
+
+// parseHeader parses numbers of the CPUs named by the header, e.g.
+// CPU0 CPU2 CPU3.
+func parseHeader(line string) ([]int, error) {
+	fields := strings.Fields(line)
+	cpus := make([]int, 0, len(fields))
+	for _, field := range fields {
+		if !strings.HasPrefix(field, "CPU") {
+			return nil, fmt.Errorf("unsupported format: %q", line)
+		}
+		cpu, err := strconv.Atoi(field[len("CPU"):])
+		if err != nil {
+			return nil, fmt.Errorf("unsupported format: %q", line)
+		}
+		cpus = append(cpus, cpu)
+	}
+	return cpus, nil
+}
+
+// hwirqRe matches the hardware interrupt number and trigger type which
+// precede device names, e.g. 524288-edge.
+var hwirqRe = regexp.MustCompile(`^\d+-\w+$`)
+
+// parseLine parses a single interrupt line, e.g.
+// 24:    1029384         12   PCI-MSI 524288-edge      eth0-TxRx-0
+// Counts may be missing for some interrupts, e.g. ERR.
+func (r *InterruptsReader) parseLine(line string, cpus int) (InterruptStat, error) {
+	colon := strings.Index(line, ":")
+	if colon <= 0 {
+		return InterruptStat{}, fmt.Errorf("unsupported format: %q", line)
+	}
+	stat := InterruptStat{
+		Name:   strings.TrimSpace(line[:colon]),
+		PerCPU: make([]uint64, cpus),
+	}
+
+	fields := strings.Fields(line[colon+1:])
+	n := 0
+	for ; n < cpus && n < len(fields); n++ {
+		v, err := strconv.ParseUint(fields[n], 10, 64)
+		if err != nil {
+			break
+		}
+		stat.PerCPU[n] = v
+	}
+	if n == 0 {
+		return InterruptStat{}, fmt.Errorf("no counts for interrupt %s", stat.Name)
+	}
+
+	desc := fields[n:]
+	if _, err := strconv.Atoi(stat.Name); err == nil && len(desc) > 1 {
+		// Skip the interrupt chip name and the hardware interrupt.
+		desc = desc[1:]
+		if len(desc) > 1 && hwirqRe.MatchString(desc[0]) {
+			desc = desc[1:]
+		}
+	}
+	stat.Device = strings.Join(desc, " ")
+	return stat, nil
+}
@@ -0,0 +1,115 @@
+package netstat
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/Bo0mer/yamt/internal"
+	"github.com/Bo0mer/yamt/metric"
+)
+
+// InterruptCollector computes per CPU interrupt rates.
+type InterruptCollector struct {
+	reader   InterruptStatReader
+	prefix   string
+	match    *regexp.Regexp
+	last     map[string]InterruptStat
+	lastTime time.Time
+}
+
+// NewInterruptCollector returns brand new interrupt collector. Event names
+// start with prefix, e.g. interrupts or softirqs. If match is not nil, only
+// interrupts whose name or device matches it are reported.
+func NewInterruptCollector(reader InterruptStatReader, prefix string, match *regexp.Regexp) (*InterruptCollector, error) {
+	c := &InterruptCollector{
+		reader: reader,
+		prefix: prefix,
+		match:  match,
+	}
+	if err := c.init(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Collect collects stats and creates events for each interrupt and CPU.
+func (c *InterruptCollector) Collect() ([]metric.Event, error) {
+	stats, actual, err := c.getState()
+	if err != nil {
+		return nil, err
+	}
+
+	actualTime := time.Now()
+	interval := actualTime.Sub(c.lastTime).Seconds()
+	rate := internal.RateComputer(interval)
+
+	events := make([]metric.Event, 0)
+	for _, stat := range stats {
+		if c.match != nil && !c.match.MatchString(stat.Name) && !c.match.MatchString(stat.Device) {
+			continue
+		}
+		last, ok := c.last[stat.Name]
+		if !ok {
+			continue
+		}
+		// Columns are matched by CPU, as CPUs may go offline or online
+		// between collections.
+		lastCounts := make(map[int]uint64, len(last.PerCPU))
+		for column, count := range last.PerCPU {
+			lastCounts[last.cpu(column)] = count
+		}
+		for column, count := range stat.PerCPU {
+			cpu := stat.cpu(column)
+			lastCount, ok := lastCounts[cpu]
+			if !ok {
+				continue
+			}
+			events = append(events, c.event(stat, cpu, rate(count, lastCount)))
+		}
+	}
+
+	c.last = actual
+	c.lastTime = actualTime
+
+	return events, nil
+}
+
+// init loads the initial state of the collector.
+func (c *InterruptCollector) init() error {
+	_, state, err := c.getState()
+	if err != nil {
+		return err
+	}
+	c.last = state
+	c.lastTime = time.Now()
+	return nil
+}
+
+// getState reads current state for all interrupts. Stats are returned in
+// the order they were read along with the state keyed by interrupt name.
+func (c *InterruptCollector) getState() ([]InterruptStat, map[string]InterruptStat, error) {
+	stats, err := c.reader.ReadStats()
+	if err != nil {
+		return nil, nil, fmt.Errorf("collector: error reading stats: %v", err)
+	}
+	state := make(map[string]InterruptStat)
+	for _, stat := range stats {
+		state[stat.Name] = stat
+	}
+	return stats, state, nil
+}
+
+func (c *InterruptCollector) event(stat InterruptStat, cpu int, value float64) metric.Event {
+	cpuName := "cpu" + strconv.Itoa(cpu)
+	attributes := map[string]string{"cpu": cpuName}
+	if stat.Device != "" {
+		attributes["device"] = stat.Device
+	}
+	return metric.Event{
+		Name:       c.prefix + " " + stat.Name + " " + cpuName,
+		Value:      value,
+		Attributes: attributes,
+	}
+}
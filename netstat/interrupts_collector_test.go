@@ -0,0 +1,106 @@
+package netstat_test
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/Bo0mer/yamt/metric"
+	"github.com/Bo0mer/yamt/netstat"
+	"github.com/Bo0mer/yamt/netstat/netstatfakes"
+)
+
+// Test that *InterruptCollector implements metric.Collector
+var _ metric.Collector = (*netstat.InterruptCollector)(nil)
+
+func TestNewInterruptCollector(t *testing.T) {
+	errReader := new(netstatfakes.FakeInterruptStatReader)
+	errReader.ReadStatsReturns(nil, errors.New("kaboom"))
+	_, err := netstat.NewInterruptCollector(errReader, "interrupts", nil)
+	if err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+func TestInterruptCollectorCollect(t *testing.T) {
+	stats := [][]netstat.InterruptStat{
+		[]netstat.InterruptStat{
+			netstat.InterruptStat{Name: "0", Device: "timer", PerCPU: []uint64{10, 10}},
+			netstat.InterruptStat{Name: "24", Device: "eth0-TxRx-0", PerCPU: []uint64{10, 10}},
+		},
+		[]netstat.InterruptStat{
+			netstat.InterruptStat{Name: "0", Device: "timer", PerCPU: []uint64{20, 20}},
+			netstat.InterruptStat{Name: "24", Device: "eth0-TxRx-0", PerCPU: []uint64{10, 20}},
+		},
+	}
+	reader := new(netstatfakes.FakeInterruptStatReader)
+	i := 0
+	reader.ReadStatsStub = func() ([]netstat.InterruptStat, error) {
+		ret := stats[i]
+		i++
+		return ret, nil
+	}
+
+	c, err := netstat.NewInterruptCollector(reader, "interrupts", regexp.MustCompile("^eth"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	got, err := c.Collect()
+	if err != nil {
+		t.Errorf("unexpected error: %v\n", err)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("want 2 events, got %v\n", got)
+	}
+	want := metric.Event{
+		Name:       "interrupts 24 cpu0",
+		Value:      0.0,
+		Attributes: map[string]string{"cpu": "cpu0", "device": "eth0-TxRx-0"},
+	}
+	if !reflect.DeepEqual(got[0], want) {
+		t.Errorf("want %v, got %v\n", want, got[0])
+	}
+	if got[1].Name != "interrupts 24 cpu1" || got[1].Value.(float64) <= 0 {
+		t.Errorf("want positive rate for cpu1, got %v\n", got[1])
+	}
+}
+
+func TestInterruptCollectorCollect_offline(t *testing.T) {
+	stats := [][]netstat.InterruptStat{
+		[]netstat.InterruptStat{
+			netstat.InterruptStat{Name: "24", PerCPU: []uint64{10, 10, 10}, CPUs: []int{0, 2, 3}},
+		},
+		[]netstat.InterruptStat{
+			netstat.InterruptStat{Name: "24", PerCPU: []uint64{10, 20}, CPUs: []int{0, 3}},
+		},
+	}
+	reader := new(netstatfakes.FakeInterruptStatReader)
+	reader.ReadStatsStub = func() ([]netstat.InterruptStat, error) {
+		ret := stats[0]
+		stats = stats[1:]
+		return ret, nil
+	}
+
+	c, err := netstat.NewInterruptCollector(reader, "interrupts", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	got, err := c.Collect()
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+
+	// Counts are attributed to CPUs named by the header, rather than to
+	// column positions.
+	if len(got) != 2 {
+		t.Fatalf("want 2 events, got %v\n", got)
+	}
+	if got[0].Name != "interrupts 24 cpu0" || got[0].Value.(float64) != 0 {
+		t.Errorf("want zero rate for cpu0, got %v\n", got[0])
+	}
+	if got[1].Name != "interrupts 24 cpu3" || got[1].Value.(float64) <= 0 {
+		t.Errorf("want positive rate for cpu3, got %v\n", got[1])
+	}
+}
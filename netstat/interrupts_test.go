@@ -0,0 +1,59 @@
+package netstat_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Bo0mer/yamt/netstat"
+)
+
+func TestInterruptsReader(t *testing.T) {
+	r := netstat.NewInterruptsReader("testdata/procInterrupts")
+	got, err := r.ReadStats()
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+
+	want := []netstat.InterruptStat{
+		netstat.InterruptStat{Name: "0", Device: "timer", PerCPU: []uint64{44, 0}, CPUs: []int{0, 1}},
+		netstat.InterruptStat{Name: "8", Device: "rtc0", PerCPU: []uint64{0, 1}, CPUs: []int{0, 1}},
+		netstat.InterruptStat{Name: "24", Device: "eth0-TxRx-0", PerCPU: []uint64{1029384, 12}, CPUs: []int{0, 1}},
+		netstat.InterruptStat{Name: "NMI", Device: "Non-maskable interrupts", PerCPU: []uint64{7, 5}, CPUs: []int{0, 1}},
+		netstat.InterruptStat{Name: "ERR", Device: "", PerCPU: []uint64{0, 0}, CPUs: []int{0, 1}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v\n", want, got)
+	}
+}
+
+func TestInterruptsReader_softirqs(t *testing.T) {
+	r := netstat.NewInterruptsReader("testdata/procSoftirqs")
+	got, err := r.ReadStats()
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+
+	want := []netstat.InterruptStat{
+		netstat.InterruptStat{Name: "HI", PerCPU: []uint64{1, 0}, CPUs: []int{0, 1}},
+		netstat.InterruptStat{Name: "NET_RX", PerCPU: []uint64{482911, 3120}, CPUs: []int{0, 1}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v\n", want, got)
+	}
+}
+
+func TestInterruptsReader_offline(t *testing.T) {
+	r := netstat.NewInterruptsReader("testdata/procInterruptsOffline")
+	got, err := r.ReadStats()
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+
+	want := []netstat.InterruptStat{
+		netstat.InterruptStat{Name: "0", Device: "timer", PerCPU: []uint64{44, 0, 3}, CPUs: []int{0, 2, 3}},
+		netstat.InterruptStat{Name: "NMI", Device: "Non-maskable interrupts", PerCPU: []uint64{7, 5, 2}, CPUs: []int{0, 2, 3}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v\n", want, got)
+	}
+}
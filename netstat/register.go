@@ -0,0 +1,119 @@
+package netstat
+
+import (
+	"fmt"
+
+	"github.com/Bo0mer/yamt/internal"
+	"github.com/Bo0mer/yamt/metric"
+	"github.com/Bo0mer/yamt/registry"
+)
+
+var ignoreInterfaces = registry.Option{
+	Name:    "ignore-interfaces",
+	Short:   "g",
+	Usage:   "Interfaces to ignore",
+	Default: "lo",
+}
+
+func init() {
+	registry.Register(registry.Collector{
+		Name:        "net",
+		Description: "Report network interface metrics",
+		Options: []registry.Option{
+			ignoreInterfaces,
+			registry.DeviceExpiry,
+			{Name: "net-total", Kind: registry.Bool, Usage: "Report network interface metrics summed across interfaces"},
+			{Name: "net-group", Kind: registry.Repeated, Usage: "Group of interfaces to report summed metrics for, in name=regexp format"},
+		},
+		Sampled: true,
+		New:     newIfStatCollector,
+	})
+	registry.Register(registry.Collector{
+		Name:        "wireless",
+		Description: "Report wireless interface signal quality, honouring -ignore-interfaces",
+		Options:     []registry.Option{ignoreInterfaces},
+		Sampled:     true,
+		New: func(c registry.Config) ([]metric.Collector, error) {
+			except, err := c.Regexp("ignore-interfaces")
+			if err != nil {
+				return nil, err
+			}
+			wc, err := NewWirelessCollector(DefaultWirelessReader, except)
+			if err != nil {
+				return nil, err
+			}
+			return []metric.Collector{metric.Named("wireless", wc)}, nil
+		},
+	})
+	registry.Register(registry.Collector{
+		Name:        "softnet",
+		Description: "Report per CPU packet processing metrics",
+		Sampled:     true,
+		New: func(c registry.Config) ([]metric.Collector, error) {
+			sc, err := NewSoftnetCollector(DefaultSoftnetReader)
+			if err != nil {
+				return nil, err
+			}
+			return []metric.Collector{metric.Named("softnet", sc)}, nil
+		},
+	})
+	registry.Register(registry.Collector{
+		Name:        "interrupts",
+		Description: "Report per CPU hardware and software interrupt metrics",
+		Options: []registry.Option{
+			{Name: "interrupts-match", Usage: "Interrupts to report, matched against name and device"},
+		},
+		Sampled: true,
+		New:     newInterruptCollectors,
+	})
+}
+
+func newIfStatCollector(c registry.Config) ([]metric.Collector, error) {
+	except, err := c.Regexp("ignore-interfaces")
+	if err != nil {
+		return nil, err
+	}
+	var opts []Option
+	total, err := c.Bool("net-total")
+	if err != nil {
+		return nil, err
+	}
+	if total {
+		opts = append(opts, Total())
+	}
+	for _, spec := range c.Strings("net-group") {
+		g, err := internal.ParseRollupGroup(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -net-group: %v", err)
+		}
+		opts = append(opts, Group(g.Name, g.Match))
+	}
+	expiry, err := c.Duration(registry.DeviceExpiry.Name)
+	if err != nil {
+		return nil, err
+	}
+	if expiry > 0 {
+		opts = append(opts, Lifecycle(expiry))
+	}
+	ic, err := NewIfStatCollector(DefaultIfStatReader, except, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return []metric.Collector{metric.Named("net", ic)}, nil
+}
+
+func newInterruptCollectors(c registry.Config) ([]metric.Collector, error) {
+	match, err := c.Regexp("interrupts-match")
+	if err != nil {
+		return nil, err
+	}
+	irqs, err := NewInterruptCollector(DefaultInterruptsReader, "interrupts", match)
+	if err != nil {
+		return nil, err
+	}
+	softirqs, err := NewInterruptCollector(DefaultSoftirqsReader, "softirqs", match)
+	if err != nil {
+		return nil, err
+	}
+	return []metric.Collector{metric.Named("interrupts", irqs), metric.Named("softirqs", softirqs)}, nil
+}
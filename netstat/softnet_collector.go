@@ -0,0 +1,94 @@
+package netstat
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Bo0mer/yamt/internal"
+	"github.com/Bo0mer/yamt/metric"
+)
+
+// SoftnetCollector computes per CPU packet processing metrics.
+type SoftnetCollector struct {
+	reader   SoftnetStatReader
+	last     map[int]SoftnetStat
+	lastTime time.Time
+}
+
+// NewSoftnetCollector returns brand new packet processing collector.
+func NewSoftnetCollector(reader SoftnetStatReader) (*SoftnetCollector, error) {
+	c := &SoftnetCollector{
+		reader: reader,
+	}
+	if err := c.init(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Collect collects stats and creates events for each CPU.
+func (c *SoftnetCollector) Collect() ([]metric.Event, error) {
+	stats, actual, err := c.getState()
+	if err != nil {
+		return nil, err
+	}
+
+	actualTime := time.Now()
+	interval := actualTime.Sub(c.lastTime).Seconds()
+	rate := internal.RateComputer(interval)
+
+	events := make([]metric.Event, 0)
+	for _, stat := range stats {
+		last, ok := c.last[stat.CPU]
+		if !ok {
+			continue
+		}
+		event := cpuEventBuilder(stat.CPU)
+		events = append(events, event("processed", rate(stat.Processed, last.Processed)))
+		events = append(events, event("dropped", rate(stat.Dropped, last.Dropped)))
+		events = append(events, event("time squeeze", rate(stat.TimeSqueeze, last.TimeSqueeze)))
+	}
+
+	c.last = actual
+	c.lastTime = actualTime
+
+	return events, nil
+}
+
+// init loads the initial state of the collector.
+func (c *SoftnetCollector) init() error {
+	_, state, err := c.getState()
+	if err != nil {
+		return err
+	}
+	c.last = state
+	c.lastTime = time.Now()
+	return nil
+}
+
+// getState reads current state for all CPUs. Stats are returned in the
+// order they were read along with the state keyed by CPU.
+func (c *SoftnetCollector) getState() ([]SoftnetStat, map[int]SoftnetStat, error) {
+	stats, err := c.reader.ReadStats()
+	if err != nil {
+		return nil, nil, fmt.Errorf("collector: error reading stats: %v", err)
+	}
+	state := make(map[int]SoftnetStat)
+	for _, stat := range stats {
+		state[stat.CPU] = stat
+	}
+	return stats, state, nil
+}
+
+// cpuEventBuilder builds events named "softnet cpu<N> <name>".
+func cpuEventBuilder(cpu int) func(string, float64) metric.Event {
+	cpuName := "cpu" + strconv.Itoa(cpu)
+	return func(name string, value float64) metric.Event {
+		return metric.Event{
+			Name:       "softnet " + cpuName + " " + name,
+			Value:      value,
+			Attributes: map[string]string{"cpu": cpuName},
+		}
+	}
+}
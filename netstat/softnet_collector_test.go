@@ -0,0 +1,67 @@
+package netstat_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Bo0mer/yamt/metric"
+	"github.com/Bo0mer/yamt/netstat"
+	"github.com/Bo0mer/yamt/netstat/netstatfakes"
+)
+
+// Test that *SoftnetCollector implements metric.Collector
+var _ metric.Collector = (*netstat.SoftnetCollector)(nil)
+
+func TestNewSoftnetCollector(t *testing.T) {
+	errReader := new(netstatfakes.FakeSoftnetStatReader)
+	errReader.ReadStatsReturns(nil, errors.New("kaboom"))
+	_, err := netstat.NewSoftnetCollector(errReader)
+	if err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+func TestSoftnetCollectorCollect(t *testing.T) {
+	stats := [][]netstat.SoftnetStat{
+		[]netstat.SoftnetStat{netstat.SoftnetStat{CPU: 3, Processed: 100, Dropped: 1}},
+		[]netstat.SoftnetStat{netstat.SoftnetStat{CPU: 3, Processed: 100, Dropped: 2}},
+	}
+	reader := new(netstatfakes.FakeSoftnetStatReader)
+	i := 0
+	reader.ReadStatsStub = func() ([]netstat.SoftnetStat, error) {
+		ret := stats[i]
+		i++
+		return ret, nil
+	}
+
+	c, err := netstat.NewSoftnetCollector(reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	got, err := c.Collect()
+	if err != nil {
+		t.Errorf("unexpected error: %v\n", err)
+	}
+
+	attributes := map[string]string{"cpu": "cpu3"}
+	want := []metric.Event{
+		metric.Event{Name: "softnet cpu3 processed", Value: 0.0, Attributes: attributes},
+		metric.Event{}, // dropped, handled separately
+		metric.Event{Name: "softnet cpu3 time squeeze", Value: 0.0, Attributes: attributes},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("want %d events, got %d\n", len(want), len(got))
+	}
+	for i := range want {
+		if i == 1 {
+			continue
+		}
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("want %v, got %v\n", want[i], got[i])
+		}
+	}
+	if got[1].Name != "softnet cpu3 dropped" || got[1].Value.(float64) <= 0 {
+		t.Errorf("want positive dropped rate, got %v\n", got[1])
+	}
+}
@@ -0,0 +1,24 @@
+package netstat_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Bo0mer/yamt/netstat"
+)
+
+func TestSoftnetReader(t *testing.T) {
+	r := netstat.NewSoftnetReader("testdata/procNetSoftnetStat")
+	got, err := r.ReadStats()
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+
+	want := []netstat.SoftnetStat{
+		netstat.SoftnetStat{CPU: 0, Processed: 574882, TimeSqueeze: 30},
+		netstat.SoftnetStat{CPU: 1, Processed: 8000, Dropped: 3, TimeSqueeze: 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v\n", want, got)
+	}
+}
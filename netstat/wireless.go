@@ -0,0 +1,129 @@
+package netstat
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/Bo0mer/yamt/internal"
+	"github.com/Bo0mer/yamt/internal/hostfs"
+)
+
+//go:generate counterfeiter . WirelessStatReader
+
+// WirelessStat represents signal quality of a wireless network interface.
+type WirelessStat struct {
+	Name string
+
+	// Link quality, on a driver specific scale.
+	Link float64
+	// Signal level, usually in dBm.
+	Level float64
+	// Noise level, usually in dBm.
+	Noise float64
+
+	// Packets discarded due to invalid network ID.
+	DiscardedNwid uint64
+	// Packets which could not be decrypted.
+	DiscardedCrypt uint64
+	// Packets which could not be reassembled.
+	DiscardedFrag uint64
+	// Packets which were not delivered after retries.
+	DiscardedRetry uint64
+	// Packets discarded for other reasons.
+	DiscardedMisc uint64
+	// Number of missed beacons.
+	MissedBeacon uint64
+}
+
+// WirelessStatReader should read signal quality of all wireless network
+// interfaces.
+type WirelessStatReader interface {
+	ReadStats() ([]WirelessStat, error)
+}
+
+// WirelessReader reads signal quality of wireless network interfaces.
+type WirelessReader struct {
+	path string
+}
+
+// NewWirelessReader creates WirelessReader that reads from the specified
+// path. Paths under /proc are resolved relative to hostfs.ProcRoot.
+func NewWirelessReader(path string) *WirelessReader {
+	return &WirelessReader{
+		path: path,
+	}
+}
+
+// DefaultWirelessReader is the default implementation of WirelessStatReader.
+// It reads signal quality from /proc/net/wireless.
+var DefaultWirelessReader WirelessStatReader = NewWirelessReader("/proc/net/wireless")
+
+// ReadWirelessStats is shorthand for DefaultWirelessReader.ReadStats.
+func ReadWirelessStats() ([]WirelessStat, error) {
+	return DefaultWirelessReader.ReadStats()
+}
+
+// ReadStats reads signal quality of all wireless network interfaces.
+func (r *WirelessReader) ReadStats() ([]WirelessStat, error) {
+	path := hostfs.Resolve(r.path)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("readwireless: error reading from %s: %v", path, err)
+	}
+	return r.parseStats(data)
+}
+
+func (r *WirelessReader) parseStats(data []byte) ([]WirelessStat, error) {
+	lines := strings.Split(string(data), "\n")
+	stats := make([]WirelessStat, 0)
+	if len(lines) < 2 {
+		// Truncated or empty, e.g. without wireless extensions.
+		return stats, nil
+	}
+	for i, line := range lines[2:] { // skip header
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		stat, err := r.parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("readwireless: error parsing line %d: %v", i+2, err)
+		}
+		stats = append(stats, stat)
+	}
+	return stats, nil
+}
+
+// parseLine parses a single interface line, e.g.
+// wlan0: 0000   54.  -56.  -256        0      3      0     12      1        0
+// Quality values are followed by a dot if they were updated since the last
+// read.
+func (r *WirelessReader) parseLine(line string) (WirelessStat, error) {
+	colon := strings.Index(line, ":")
+	if colon <= 0 {
+		return WirelessStat{}, fmt.Errorf("unsupported format: %q", line)
+	}
+	fields := strings.Fields(line[colon+1:])
+	if len(fields) < 10 {
+		return WirelessStat{}, fmt.Errorf("unsupported format: %q", line)
+	}
+
+	p := &internal.ErrParser{}
+	stat := WirelessStat{
+		Name: strings.TrimSpace(line[:colon]),
+		// fields[0] is status
+		Link:           p.ParseFloat64(strings.TrimSuffix(fields[1], ".")),
+		Level:          p.ParseFloat64(strings.TrimSuffix(fields[2], ".")),
+		Noise:          p.ParseFloat64(strings.TrimSuffix(fields[3], ".")),
+		DiscardedNwid:  p.ParseUint64(fields[4]),
+		DiscardedCrypt: p.ParseUint64(fields[5]),
+		DiscardedFrag:  p.ParseUint64(fields[6]),
+		DiscardedRetry: p.ParseUint64(fields[7]),
+		DiscardedMisc:  p.ParseUint64(fields[8]),
+		MissedBeacon:   p.ParseUint64(fields[9]),
+	}
+	if err := p.Err(); err != nil {
+		return WirelessStat{}, fmt.Errorf("error reading stats for %s: %v", stat.Name, err)
+	}
+	return stat, nil
+}
@@ -0,0 +1,106 @@
+package netstat
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/Bo0mer/yamt/internal"
+	"github.com/Bo0mer/yamt/metric"
+)
+
+// WirelessCollector computes metrics for wireless network interfaces.
+type WirelessCollector struct {
+	reader   WirelessStatReader
+	except   *regexp.Regexp
+	last     map[string]WirelessStat
+	lastTime time.Time
+}
+
+// NewWirelessCollector returns brand new wireless interface collector.
+// Interfaces matching except are not reported.
+func NewWirelessCollector(reader WirelessStatReader, except *regexp.Regexp) (*WirelessCollector, error) {
+	c := &WirelessCollector{
+		reader: reader,
+		except: except,
+	}
+	if err := c.init(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Collect collects stats and creates events for wireless network
+// interfaces. Signal quality is reported as is, discarded packets as rates.
+func (c *WirelessCollector) Collect() ([]metric.Event, error) {
+	stats, actual, err := c.getState()
+	if err != nil {
+		return nil, err
+	}
+
+	actualTime := time.Now()
+	interval := actualTime.Sub(c.lastTime).Seconds()
+
+	events := make([]metric.Event, 0)
+	for _, stat := range stats {
+		if c.except != nil && c.except.MatchString(stat.Name) {
+			continue
+		}
+		last, ok := c.last[stat.Name]
+		if !ok {
+			continue
+		}
+		events = append(events, c.buildEvents(stat, last, interval)...)
+	}
+
+	c.last = actual
+	c.lastTime = actualTime
+
+	return events, nil
+}
+
+// init loads the initial state of the collector.
+func (c *WirelessCollector) init() error {
+	_, state, err := c.getState()
+	if err != nil {
+		return err
+	}
+	c.last = state
+	c.lastTime = time.Now()
+	return nil
+}
+
+// getState reads current state for all wireless network interfaces. Stats
+// are returned in the order they were read along with the state keyed by
+// interface name.
+func (c *WirelessCollector) getState() ([]WirelessStat, map[string]WirelessStat, error) {
+	stats, err := c.reader.ReadStats()
+	if err != nil {
+		return nil, nil, fmt.Errorf("collector: error reading stats: %v", err)
+	}
+	state := make(map[string]WirelessStat)
+	for _, stat := range stats {
+		state[stat.Name] = stat
+	}
+	return stats, state, nil
+}
+
+// buildEvents build all events for a single wireless network interface.
+func (c *WirelessCollector) buildEvents(actual, last WirelessStat, interval float64) []metric.Event {
+	events := make([]metric.Event, 0)
+	event := eventBuilder(actual.Name)
+	rate := internal.RateComputer(interval)
+
+	events = append(events, event("link quality", actual.Link))
+	events = append(events, event("signal level(dBm)", actual.Level))
+	events = append(events, event("noise level(dBm)", actual.Noise))
+
+	events = append(events, event("discarded nwid", rate(actual.DiscardedNwid, last.DiscardedNwid)))
+	events = append(events, event("discarded crypt", rate(actual.DiscardedCrypt, last.DiscardedCrypt)))
+	events = append(events, event("discarded frag", rate(actual.DiscardedFrag, last.DiscardedFrag)))
+	events = append(events, event("discarded retry", rate(actual.DiscardedRetry, last.DiscardedRetry)))
+	events = append(events, event("discarded misc", rate(actual.DiscardedMisc, last.DiscardedMisc)))
+	events = append(events, event("missed beacon", rate(actual.MissedBeacon, last.MissedBeacon)))
+
+	return events
+}
@@ -0,0 +1,83 @@
+package netstat_test
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/Bo0mer/yamt/metric"
+	"github.com/Bo0mer/yamt/netstat"
+	"github.com/Bo0mer/yamt/netstat/netstatfakes"
+)
+
+// Test that *WirelessCollector implements metric.Collector
+var _ metric.Collector = (*netstat.WirelessCollector)(nil)
+
+func TestNewWirelessCollector(t *testing.T) {
+	errReader := new(netstatfakes.FakeWirelessStatReader)
+	errReader.ReadStatsReturns(nil, errors.New("kaboom"))
+	_, err := netstat.NewWirelessCollector(errReader, nil)
+	if err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+func newFakedWirelessReader() netstat.WirelessStatReader {
+	stats := [][]netstat.WirelessStat{
+		[]netstat.WirelessStat{
+			netstat.WirelessStat{Name: "wlan0", Link: 50, Level: -60, Noise: -95, DiscardedRetry: 10},
+			netstat.WirelessStat{Name: "wlan1", Link: 50},
+		},
+		[]netstat.WirelessStat{
+			netstat.WirelessStat{Name: "wlan0", Link: 54, Level: -56, Noise: -95, DiscardedRetry: 20},
+			netstat.WirelessStat{Name: "wlan1", Link: 50},
+		},
+	}
+	r := new(netstatfakes.FakeWirelessStatReader)
+	i := 0
+	r.ReadStatsStub = func() ([]netstat.WirelessStat, error) {
+		ret := stats[i]
+		i++
+		return ret, nil
+	}
+	return r
+}
+
+func TestWirelessCollectorCollect(t *testing.T) {
+	want := []metric.Event{
+		metric.Event{Name: "wlan0 link quality", Value: 54.0},
+		metric.Event{Name: "wlan0 signal level(dBm)", Value: -56.0},
+		metric.Event{Name: "wlan0 noise level(dBm)", Value: -95.0},
+		metric.Event{Name: "wlan0 discarded nwid", Value: 0.0},
+		metric.Event{Name: "wlan0 discarded crypt", Value: 0.0},
+		metric.Event{Name: "wlan0 discarded frag", Value: 0.0},
+		metric.Event{}, // discarded retry, handled separately
+		metric.Event{Name: "wlan0 discarded misc", Value: 0.0},
+		metric.Event{Name: "wlan0 missed beacon", Value: 0.0},
+	}
+
+	c, err := netstat.NewWirelessCollector(newFakedWirelessReader(), regexp.MustCompile("wlan1"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	got, err := c.Collect()
+	if err != nil {
+		t.Errorf("unexpected error: %v\n", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d events, got %d\n", len(want), len(got))
+	}
+
+	for i := range want {
+		if i == 6 {
+			if got[i].Name != "wlan0 discarded retry" || got[i].Value.(float64) <= 0 {
+				t.Errorf("expected positive discarded retry rate, got %v\n", got[i])
+			}
+			continue
+		}
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("expected %#v, got %#v\n", want[i], got[i])
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package netstat_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Bo0mer/yamt/netstat"
+)
+
+func TestWirelessReader(t *testing.T) {
+	r := netstat.NewWirelessReader("testdata/procNetWireless")
+	got, err := r.ReadStats()
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+
+	want := []netstat.WirelessStat{
+		netstat.WirelessStat{
+			Name:           "wlan0",
+			Link:           54,
+			Level:          -56,
+			Noise:          -256,
+			DiscardedCrypt: 3,
+			DiscardedRetry: 12,
+			DiscardedMisc:  1,
+		},
+		netstat.WirelessStat{
+			Name:          "wlp3s0",
+			Link:          70,
+			Level:         -40,
+			Noise:         -95,
+			DiscardedNwid: 1,
+			MissedBeacon:  7,
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v\n", want, got)
+	}
+}
+
+func TestWirelessReader_empty(t *testing.T) {
+	r := netstat.NewWirelessReader("testdata/procNetWirelessEmpty")
+	got, err := r.ReadStats()
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("expected no interfaces, got %v\n", got)
+	}
+}
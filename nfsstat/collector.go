@@ -0,0 +1,161 @@
+package nfsstat
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Bo0mer/yamt/internal"
+	"github.com/Bo0mer/yamt/metric"
+)
+
+type state map[string]MountStat
+
+// MountStatCollector computes metrics for NFS mounts.
+type MountStatCollector struct {
+	reader   MountStatReader
+	ops      map[string]bool
+	last     state
+	lastTime time.Time
+}
+
+// NewMountStatCollector returns brand new NFS mount collector. Per operation
+// metrics are reported only for the specified operations, e.g. READ or
+// GETATTR, or for all operations if ops is empty.
+func NewMountStatCollector(reader MountStatReader, ops []string) (*MountStatCollector, error) {
+	c := &MountStatCollector{
+		reader: reader,
+	}
+	if len(ops) > 0 {
+		c.ops = make(map[string]bool)
+		for _, op := range ops {
+			c.ops[op] = true
+		}
+	}
+	if err := c.init(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Collect collects stats and creates events for NFS mounts.
+func (c *MountStatCollector) Collect() ([]metric.Event, error) {
+	actual, err := c.getState()
+	if err != nil {
+		return nil, err
+	}
+
+	actualTime := time.Now()
+	interval := actualTime.Sub(c.lastTime).Seconds()
+
+	events := make([]metric.Event, 0)
+
+	for _, stat := range actual {
+		last, ok := c.last[stat.MountPoint]
+		if !ok {
+			continue
+		}
+
+		events = append(events, c.buildEvents(stat, last, interval)...)
+	}
+
+	c.last = actual
+	c.lastTime = actualTime
+
+	return events, nil
+}
+
+// init loads the initial state of the collector.
+func (c *MountStatCollector) init() error {
+	state, err := c.getState()
+	if err != nil {
+		return err
+	}
+	c.last = state
+	c.lastTime = time.Now()
+	return nil
+}
+
+// getState reads current state for all NFS mounts.
+func (c *MountStatCollector) getState() (state, error) {
+	state := make(map[string]MountStat)
+	stats, err := c.reader.ReadStats()
+	if err != nil {
+		return nil, fmt.Errorf("collector: error reading stats: %v", err)
+	}
+	for _, stat := range stats {
+		state[stat.MountPoint] = stat
+	}
+	return state, nil
+}
+
+// buildEvents builds all events for a single NFS mount. Average RTT and
+// execution time are computed from operations completed in the interval.
+func (c *MountStatCollector) buildEvents(actual, last MountStat, interval float64) []metric.Event {
+	events := make([]metric.Event, 0)
+	event := eventBuilder(actual)
+	rate := internal.RateComputer(interval)
+
+	events = append(events, event("read bytes", rate(actual.ReadBytes, last.ReadBytes)))
+	events = append(events, event("write bytes", rate(actual.WriteBytes, last.WriteBytes)))
+	events = append(events, event("server read bytes", rate(actual.ServerReadBytes, last.ServerReadBytes)))
+	events = append(events, event("server write bytes", rate(actual.ServerWriteBytes, last.ServerWriteBytes)))
+
+	names := make([]string, 0, len(actual.Ops))
+	for name := range actual.Ops {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var retrans float64
+	for _, name := range names {
+		op := actual.Ops[name]
+		lastOp, ok := last.Ops[name]
+		if !ok {
+			continue
+		}
+		opRetrans := rate(delta(op.Transmissions, op.Ops), delta(lastOp.Transmissions, lastOp.Ops))
+		retrans += opRetrans
+		if c.ops != nil && !c.ops[name] {
+			continue
+		}
+
+		ops := delta(op.Ops, lastOp.Ops)
+		events = append(events, event(name+" ops", rate(op.Ops, lastOp.Ops)))
+		events = append(events, event(name+" retrans", opRetrans))
+		events = append(events, event(name+" rtt(ms)", average(delta(op.RTTMs, lastOp.RTTMs), ops)))
+		events = append(events, event(name+" exec(ms)", average(delta(op.ExecuteMs, lastOp.ExecuteMs), ops)))
+	}
+	events = append(events, event("retrans", retrans))
+
+	return events
+}
+
+// delta returns a - b, or zero if b is greater, e.g. after remount.
+func delta(a, b uint64) uint64 {
+	if a < b {
+		return 0
+	}
+	return a - b
+}
+
+// average returns total divided by count, or zero if count is zero.
+func average(total, count uint64) float64 {
+	if count == 0 {
+		return 0
+	}
+	return float64(total) / float64(count)
+}
+
+func eventBuilder(m MountStat) func(string, float64) metric.Event {
+	return func(name string, value float64) metric.Event {
+		return metric.Event{
+			Name:  "nfs " + m.MountPoint + " " + name,
+			Value: value,
+			Attributes: map[string]string{
+				"mountpoint": m.MountPoint,
+				"server":     m.Server,
+			},
+		}
+	}
+}
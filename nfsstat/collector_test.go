@@ -0,0 +1,99 @@
+package nfsstat_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Bo0mer/yamt/metric"
+	"github.com/Bo0mer/yamt/nfsstat"
+	"github.com/Bo0mer/yamt/nfsstat/nfsstatfakes"
+)
+
+// Test that *MountStatCollector implements metric.Collector
+var _ metric.Collector = (*nfsstat.MountStatCollector)(nil)
+
+func TestNewMountStatCollector(t *testing.T) {
+	errReader := new(nfsstatfakes.FakeMountStatReader)
+	errReader.ReadStatsReturns(nil, errors.New("kaboom"))
+	_, err := nfsstat.NewMountStatCollector(errReader, nil)
+	if err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+var stats = map[int][]nfsstat.MountStat{
+	0: []nfsstat.MountStat{
+		nfsstat.MountStat{
+			Server:     "fileserver",
+			MountPoint: "/mnt/builds",
+			Ops: map[string]nfsstat.OpStat{
+				"READ":    nfsstat.OpStat{Ops: 10, Transmissions: 10, RTTMs: 100, ExecuteMs: 120},
+				"GETATTR": nfsstat.OpStat{Ops: 10, Transmissions: 10},
+			},
+		},
+	},
+	1: []nfsstat.MountStat{
+		nfsstat.MountStat{
+			Server:     "fileserver",
+			MountPoint: "/mnt/builds",
+			Ops: map[string]nfsstat.OpStat{
+				"READ":    nfsstat.OpStat{Ops: 14, Transmissions: 15, RTTMs: 120, ExecuteMs: 160},
+				"GETATTR": nfsstat.OpStat{Ops: 10, Transmissions: 10},
+			},
+		},
+	},
+}
+
+func newFakedReader(t *testing.T) nfsstat.MountStatReader {
+	r := new(nfsstatfakes.FakeMountStatReader)
+	i := 0
+	r.ReadStatsStub = func() ([]nfsstat.MountStat, error) {
+		ret := stats[i]
+		i++
+		return ret, nil
+	}
+	return r
+}
+
+func TestMountStatCollectorCollect(t *testing.T) {
+	attributes := map[string]string{"mountpoint": "/mnt/builds", "server": "fileserver"}
+	want := []metric.Event{
+		metric.Event{Name: "nfs /mnt/builds read bytes", Value: 0.0, Attributes: attributes},
+		metric.Event{Name: "nfs /mnt/builds write bytes", Value: 0.0, Attributes: attributes},
+		metric.Event{Name: "nfs /mnt/builds server read bytes", Value: 0.0, Attributes: attributes},
+		metric.Event{Name: "nfs /mnt/builds server write bytes", Value: 0.0, Attributes: attributes},
+		metric.Event{}, // READ ops, handled separately
+		metric.Event{}, // READ retrans, handled separately
+		metric.Event{Name: "nfs /mnt/builds READ rtt(ms)", Value: 5.0, Attributes: attributes},
+		metric.Event{Name: "nfs /mnt/builds READ exec(ms)", Value: 10.0, Attributes: attributes},
+		metric.Event{}, // retrans, handled separately
+	}
+
+	c, err := nfsstat.NewMountStatCollector(newFakedReader(t), []string{"READ"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	got, err := c.Collect()
+	if err != nil {
+		t.Errorf("unexpected error: %v\n", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d events, got %d\n", len(want), len(got))
+	}
+
+	for i := range got {
+		switch got[i].Name {
+		case "nfs /mnt/builds READ ops", "nfs /mnt/builds READ retrans", "nfs /mnt/builds retrans":
+			if f, ok := got[i].Value.(float64); !ok {
+				t.Errorf("expected float64 value, got %T\n", got[i].Value)
+			} else if f <= 0 {
+				t.Errorf("expected positive value for %s, got %f\n", got[i].Name, f)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("expected %#v, got %#v\n", want[i], got[i])
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package nfsstat
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/Bo0mer/yamt/internal"
+	"github.com/Bo0mer/yamt/internal/hostfs"
+)
+
+//go:generate counterfeiter . MountStatReader
+
+// OpStat represents statistics for a single NFS operation, e.g. READ.
+type OpStat struct {
+	// Number of operations.
+	Ops uint64
+	// Number of transmissions, retransmissions included.
+	Transmissions uint64
+	// Number of major timeouts.
+	MajorTimeouts uint64
+	// Bytes sent, headers included.
+	BytesSent uint64
+	// Bytes received, headers included.
+	BytesRecv uint64
+	// Cumulative time requests spent queued, in milliseconds.
+	QueueMs uint64
+	// Cumulative round trip time, in milliseconds.
+	RTTMs uint64
+	// Cumulative execution time, queueing included, in milliseconds.
+	ExecuteMs uint64
+}
+
+// MountStat represents statistics for a single NFS mount.
+type MountStat struct {
+	// Exported filesystem, e.g. server:/export.
+	Device string
+	// Server part of Device.
+	Server string
+	// Where the filesystem is mounted.
+	MountPoint string
+	// Filesystem type, nfs or nfs4.
+	FSType string
+
+	// Bytes read by applications, direct I/O included.
+	ReadBytes uint64
+	// Bytes written by applications, direct I/O included.
+	WriteBytes uint64
+	// Bytes read from the server.
+	ServerReadBytes uint64
+	// Bytes written to the server.
+	ServerWriteBytes uint64
+
+	// Per operation statistics, keyed by operation name.
+	Ops map[string]OpStat
+}
+
+// MountStatReader should read statistics for all NFS mounts.
+type MountStatReader interface {
+	ReadStats() ([]MountStat, error)
+}
+
+// MountstatsReader reads NFS mount statistics.
+type MountstatsReader struct {
+	path string
+}
+
+// NewMountstatsReader creates MountstatsReader that reads from the specified
+// path. Paths under /proc are resolved relative to hostfs.ProcRoot.
+func NewMountstatsReader(path string) *MountstatsReader {
+	return &MountstatsReader{
+		path: path,
+	}
+}
+
+// DefaultMountstatsReader is the default implementation of MountStatReader.
+// It reads NFS mount statistics from /proc/self/mountstats.
+var DefaultMountstatsReader MountStatReader = NewMountstatsReader("/proc/self/mountstats")
+
+// ReadMountStats is shorthand for DefaultMountstatsReader.ReadStats.
+func ReadMountStats() ([]MountStat, error) {
+	return DefaultMountstatsReader.ReadStats()
+}
+
+// ReadStats reads statistics for all NFS mounts. Other mounts are skipped.
+func (r *MountstatsReader) ReadStats() ([]MountStat, error) {
+	path := hostfs.Resolve(r.path)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("readmountstats: error reading from %s: %v", path, err)
+	}
+	return r.parseStats(data)
+}
+
+func (r *MountstatsReader) parseStats(data []byte) ([]MountStat, error) {
+	stats := make([]MountStat, 0)
+	var current *MountStat
+	inOps := false
+
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for i := 0; s.Scan(); i++ {
+		line := s.Text()
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if fields[0] == "device" {
+			if current != nil {
+				stats = append(stats, *current)
+			}
+			current, inOps = r.parseDevice(fields), false
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		var err error
+		switch {
+		case fields[0] == "bytes:":
+			err = r.parseBytes(current, fields[1:])
+		case strings.TrimSpace(line) == "per-op statistics":
+			inOps = true
+		case inOps && strings.HasSuffix(fields[0], ":"):
+			err = r.parseOp(current, fields)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("readmountstats: error parsing line %d: %v", i, err)
+		}
+	}
+	if current != nil {
+		stats = append(stats, *current)
+	}
+	return stats, nil
+}
+
+// parseDevice parses a line in the following format, returning nil for
+// non NFS mounts:
+// device server:/export mounted on /mnt with fstype nfs4 statvers=1.1
+func (r *MountstatsReader) parseDevice(fields []string) *MountStat {
+	if len(fields) < 8 || fields[2] != "mounted" || fields[5] != "with" {
+		return nil
+	}
+	fstype := fields[7]
+	if fstype != "nfs" && fstype != "nfs4" {
+		return nil
+	}
+	stat := &MountStat{
+		Device:     fields[1],
+		MountPoint: fields[4],
+		FSType:     fstype,
+		Ops:        make(map[string]OpStat),
+	}
+	if colon := strings.Index(stat.Device, ":"); colon > 0 {
+		stat.Server = stat.Device[:colon]
+	}
+	return stat
+}
+
+func (r *MountstatsReader) parseBytes(stat *MountStat, fields []string) error {
+	if len(fields) < 6 {
+		return fmt.Errorf("unsupported bytes format: %v", fields)
+	}
+	p := &internal.ErrParser{}
+	stat.ReadBytes = p.ParseUint64(fields[0]) + p.ParseUint64(fields[2])
+	stat.WriteBytes = p.ParseUint64(fields[1]) + p.ParseUint64(fields[3])
+	stat.ServerReadBytes = p.ParseUint64(fields[4])
+	stat.ServerWriteBytes = p.ParseUint64(fields[5])
+	return p.Err()
+}
+
+func (r *MountstatsReader) parseOp(stat *MountStat, fields []string) error {
+	if len(fields) < 9 {
+		return fmt.Errorf("unsupported operation format: %v", fields)
+	}
+	p := &internal.ErrParser{}
+	op := OpStat{
+		Ops:           p.ParseUint64(fields[1]),
+		Transmissions: p.ParseUint64(fields[2]),
+		MajorTimeouts: p.ParseUint64(fields[3]),
+		BytesSent:     p.ParseUint64(fields[4]),
+		BytesRecv:     p.ParseUint64(fields[5]),
+		QueueMs:       p.ParseUint64(fields[6]),
+		RTTMs:         p.ParseUint64(fields[7]),
+		ExecuteMs:     p.ParseUint64(fields[8]),
+	}
+	if err := p.Err(); err != nil {
+		return err
+	}
+	stat.Ops[strings.TrimSuffix(fields[0], ":")] = op
+	return nil
+}
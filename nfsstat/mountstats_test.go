@@ -0,0 +1,53 @@
+package nfsstat_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Bo0mer/yamt/nfsstat"
+)
+
+func TestMountstatsReader(t *testing.T) {
+	r := nfsstat.NewMountstatsReader("testdata/mountstats")
+	got, err := r.ReadStats()
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+
+	want := []nfsstat.MountStat{
+		nfsstat.MountStat{
+			Device:           "fileserver:/export/builds",
+			Server:           "fileserver",
+			MountPoint:       "/mnt/builds",
+			FSType:           "nfs4",
+			ReadBytes:        1207640230,
+			WriteBytes:       512,
+			ServerReadBytes:  1207640230,
+			ServerWriteBytes: 512,
+			Ops: map[string]nfsstat.OpStat{
+				"NULL":    nfsstat.OpStat{},
+				"READ":    nfsstat.OpStat{Ops: 1298, Transmissions: 1300, BytesSent: 207680, BytesRecv: 1210292152, QueueMs: 6, RTTMs: 79386, ExecuteMs: 79407},
+				"WRITE":   nfsstat.OpStat{Ops: 1, Transmissions: 1, BytesSent: 236, BytesRecv: 136, RTTMs: 2, ExecuteMs: 2},
+				"GETATTR": nfsstat.OpStat{Ops: 49, Transmissions: 49, BytesSent: 9212, BytesRecv: 11172, QueueMs: 2, RTTMs: 37, ExecuteMs: 41},
+				"LOOKUP":  nfsstat.OpStat{Ops: 28, Transmissions: 28, BytesSent: 5684, BytesRecv: 8988, RTTMs: 20, ExecuteMs: 23},
+			},
+		},
+		nfsstat.MountStat{
+			Device:           "otherserver:/home",
+			Server:           "otherserver",
+			MountPoint:       "/mnt/home",
+			FSType:           "nfs",
+			ReadBytes:        40,
+			WriteBytes:       60,
+			ServerReadBytes:  50,
+			ServerWriteBytes: 60,
+			Ops: map[string]nfsstat.OpStat{
+				"READ": nfsstat.OpStat{Ops: 5, Transmissions: 5, RTTMs: 10, ExecuteMs: 12},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v\n\tgot %v\n", want, got)
+	}
+}
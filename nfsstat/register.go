@@ -0,0 +1,31 @@
+package nfsstat
+
+import (
+	"strings"
+
+	"github.com/Bo0mer/yamt/internal/hostfs"
+	"github.com/Bo0mer/yamt/metric"
+	"github.com/Bo0mer/yamt/registry"
+)
+
+func init() {
+	registry.Register(registry.Collector{
+		Name:        "nfs",
+		Description: "Report NFS client metrics",
+		Options: []registry.Option{
+			{Name: "nfs-ops", Usage: "Comma separated NFS operations to report, all if empty"},
+		},
+		New: func(c registry.Config) ([]metric.Collector, error) {
+			var ops []string
+			if s := c.String("nfs-ops"); s != "" {
+				ops = strings.Split(s, ",")
+			}
+			reader := NewMountstatsReader(hostfs.MountsFile("mountstats"))
+			mc, err := NewMountStatCollector(reader, ops)
+			if err != nil {
+				return nil, err
+			}
+			return []metric.Collector{metric.Named("nfs", mc)}, nil
+		},
+	})
+}
@@ -0,0 +1,150 @@
+package procstat
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Bo0mer/yamt/metric"
+)
+
+// userHZ is the number of clock ticks per second used by the kernel when
+// reporting process times to user space.
+const userHZ = 100
+
+// processKey identifies a process across collections, as PIDs may be reused.
+type processKey struct {
+	pid       int
+	startTime uint64
+}
+
+type state map[processKey]ProcessStat
+
+// ProcessCollector computes metrics for groups of processes.
+type ProcessCollector struct {
+	reader   ProcessStatReader
+	groups   []Group
+	last     state
+	lastTime time.Time
+}
+
+// NewProcessCollector returns brand new process collector which reports
+// aggregated metrics for each of the specified groups.
+func NewProcessCollector(reader ProcessStatReader, groups []Group) (*ProcessCollector, error) {
+	c := &ProcessCollector{
+		reader: reader,
+		groups: groups,
+	}
+	if err := c.init(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Collect collects stats and creates events for each process group.
+// Rates are computed only from processes which were present in the previous
+// collection as well, so processes that start or exit between two
+// collections do not cause spikes.
+func (c *ProcessCollector) Collect() ([]metric.Event, error) {
+	actual, err := c.getState()
+	if err != nil {
+		return nil, err
+	}
+
+	actualTime := time.Now()
+	interval := actualTime.Sub(c.lastTime).Seconds()
+
+	for _, g := range c.groups {
+		if r, ok := g.Matcher.(refresher); ok {
+			r.refresh()
+		}
+	}
+
+	events := make([]metric.Event, 0)
+	for _, g := range c.groups {
+		events = append(events, c.buildEvents(g, actual, interval)...)
+	}
+
+	c.last = actual
+	c.lastTime = actualTime
+
+	return events, nil
+}
+
+// init loads the initial state of the collector.
+func (c *ProcessCollector) init() error {
+	state, err := c.getState()
+	if err != nil {
+		return err
+	}
+	c.last = state
+	c.lastTime = time.Now()
+	return nil
+}
+
+// getState reads current state for all processes.
+func (c *ProcessCollector) getState() (state, error) {
+	state := make(map[processKey]ProcessStat)
+	stats, err := c.reader.ReadStats()
+	if err != nil {
+		return nil, fmt.Errorf("collector: error reading stats: %v", err)
+	}
+	for _, stat := range stats {
+		state[processKey{stat.PID, stat.StartTime}] = stat
+	}
+	return state, nil
+}
+
+// buildEvents builds all events for a single process group.
+func (c *ProcessCollector) buildEvents(g Group, actual state, interval float64) []metric.Event {
+	var count, threads, rss, fds uint64
+	var cpuTicks, readBytes, writeBytes uint64
+
+	for key, stat := range actual {
+		if !g.Matcher.Match(stat) {
+			continue
+		}
+		count++
+		threads += stat.Threads
+		rss += stat.RSS
+		fds += stat.FDs
+
+		last, ok := c.last[key]
+		if !ok {
+			continue
+		}
+		cpuTicks += delta(stat.UTime+stat.STime, last.UTime+last.STime)
+		readBytes += delta(stat.ReadBytes, last.ReadBytes)
+		writeBytes += delta(stat.WriteBytes, last.WriteBytes)
+	}
+
+	events := make([]metric.Event, 0)
+	event := eventBuilder("process " + g.Name)
+
+	events = append(events, event("count", float64(count)))
+	events = append(events, event("threads", float64(threads)))
+	events = append(events, event("rss(bytes)", float64(rss)))
+	events = append(events, event("fds", float64(fds)))
+
+	events = append(events, event("cpu(%)", float64(cpuTicks)/userHZ/interval*100))
+	events = append(events, event("read bytes", float64(readBytes)/interval))
+	events = append(events, event("write bytes", float64(writeBytes)/interval))
+
+	return events
+}
+
+// delta returns the increase of a counter, treating decreases as no change.
+func delta(actual, last uint64) uint64 {
+	if actual < last {
+		return 0
+	}
+	return actual - last
+}
+
+func eventBuilder(prefix string) func(string, float64) metric.Event {
+	return func(name string, value float64) metric.Event {
+		return metric.Event{
+			Name:  prefix + " " + name,
+			Value: value,
+		}
+	}
+}
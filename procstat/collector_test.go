@@ -0,0 +1,105 @@
+package procstat_test
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/Bo0mer/yamt/metric"
+	"github.com/Bo0mer/yamt/procstat"
+	"github.com/Bo0mer/yamt/procstat/procstatfakes"
+)
+
+// Test that *ProcessCollector implements metric.Collector
+var _ metric.Collector = (*procstat.ProcessCollector)(nil)
+
+func TestNewProcessCollector(t *testing.T) {
+	_, err := procstat.NewProcessCollector(procstat.DefaultProcReader, nil)
+	if err != nil {
+		t.Errorf("unexpected error: %v\n", err)
+	}
+
+	errReader := new(procstatfakes.FakeProcessStatReader)
+	errReader.ReadStatsReturns(nil, errors.New("kaboom"))
+	_, err = procstat.NewProcessCollector(errReader, nil)
+	if err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+var stats = map[int][]procstat.ProcessStat{
+	0: []procstat.ProcessStat{
+		procstat.ProcessStat{PID: 10, Comm: "postgres", StartTime: 1, UTime: 100, WriteBytes: 1000, RSS: 1024, Threads: 2},
+		procstat.ProcessStat{PID: 11, Comm: "postgres", StartTime: 1, UTime: 100, WriteBytes: 5000, RSS: 1024, Threads: 1},
+	},
+	1: []procstat.ProcessStat{
+		procstat.ProcessStat{PID: 10, Comm: "postgres", StartTime: 1, UTime: 200, WriteBytes: 2000, RSS: 2048, Threads: 2},
+		// PID reused by a new process, must not be considered for rates.
+		procstat.ProcessStat{PID: 11, Comm: "postgres", StartTime: 9, UTime: 0, WriteBytes: 0, RSS: 1024, Threads: 1},
+		// New process, must not be considered for rates.
+		procstat.ProcessStat{PID: 12, Comm: "postgres", StartTime: 9, UTime: 90000, WriteBytes: 90000, RSS: 1024, Threads: 1},
+		procstat.ProcessStat{PID: 13, Comm: "nginx", StartTime: 9, RSS: 4096, Threads: 1},
+	},
+}
+
+func newFakedReader(t *testing.T) procstat.ProcessStatReader {
+	r := new(procstatfakes.FakeProcessStatReader)
+	i := 0
+	r.ReadStatsStub = func() ([]procstat.ProcessStat, error) {
+		ret := stats[i]
+		i++
+		return ret, nil
+	}
+	return r
+}
+
+func TestProcessCollectorCollect(t *testing.T) {
+	want := []metric.Event{
+		metric.Event{Name: "process postgres count", Value: 3.0},
+		metric.Event{Name: "process postgres threads", Value: 4.0},
+		metric.Event{Name: "process postgres rss(bytes)", Value: 4096.0},
+		metric.Event{Name: "process postgres fds", Value: 0.0},
+		metric.Event{}, // cpu(%), handled separately
+		metric.Event{Name: "process postgres read bytes", Value: 0.0},
+		metric.Event{}, // write bytes, handled separately
+	}
+
+	groups := []procstat.Group{
+		procstat.Group{Name: "postgres", Matcher: procstat.NewCommMatcher(regexp.MustCompile("^postgres$"))},
+	}
+	c, err := procstat.NewProcessCollector(newFakedReader(t), groups)
+	if err != nil {
+		t.Errorf("unexpected error: %v\n", err)
+	}
+	got, err := c.Collect()
+	if err != nil {
+		t.Errorf("unexpected error: %v\n", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d events, got %d\n", len(want), len(got))
+	}
+
+	var cpu, written float64
+	for i := range got {
+		switch got[i].Name {
+		case "process postgres cpu(%)":
+			cpu, _ = got[i].Value.(float64)
+			continue
+		case "process postgres write bytes":
+			written, _ = got[i].Value.(float64)
+			continue
+		}
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("expected %#v, got %#v\n", want[i], got[i])
+		}
+	}
+
+	// Only process 10 contributes to rates with 1 second of CPU time, i.e.
+	// 100%, and 1000 bytes written per interval. New processes and reused
+	// PIDs would skew the ratio.
+	if cpu <= 0 || math.Abs(written/cpu-10) > 1e-6 {
+		t.Errorf("expected write bytes to cpu ratio of 10, got %f/%f\n", written, cpu)
+	}
+}
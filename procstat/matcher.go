@@ -0,0 +1,159 @@
+package procstat
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os/user"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Matcher decides whether a process belongs to a group.
+type Matcher interface {
+	Match(ProcessStat) bool
+}
+
+// refresher is implemented by matchers which need to reload their state
+// once before each collection.
+type refresher interface {
+	refresh()
+}
+
+// Group represents a named set of processes whose metrics are aggregated.
+type Group struct {
+	Name    string
+	Matcher Matcher
+}
+
+// CommMatcher matches processes by executable name.
+type CommMatcher struct {
+	re *regexp.Regexp
+}
+
+// NewCommMatcher returns matcher for processes whose executable name matches
+// re.
+func NewCommMatcher(re *regexp.Regexp) *CommMatcher {
+	return &CommMatcher{re: re}
+}
+
+// Match implements Matcher.
+func (m *CommMatcher) Match(p ProcessStat) bool {
+	return m.re.MatchString(p.Comm)
+}
+
+// CmdlineMatcher matches processes by command line.
+type CmdlineMatcher struct {
+	re *regexp.Regexp
+}
+
+// NewCmdlineMatcher returns matcher for processes whose command line,
+// with arguments separated by spaces, matches re.
+func NewCmdlineMatcher(re *regexp.Regexp) *CmdlineMatcher {
+	return &CmdlineMatcher{re: re}
+}
+
+// Match implements Matcher.
+func (m *CmdlineMatcher) Match(p ProcessStat) bool {
+	return m.re.MatchString(p.Cmdline)
+}
+
+// UserMatcher matches processes by their real user ID.
+type UserMatcher struct {
+	uid int
+}
+
+// NewUserMatcher returns matcher for processes owned by the specified user.
+// The user may be specified by name or by numeric ID.
+func NewUserMatcher(name string) (*UserMatcher, error) {
+	if uid, err := strconv.Atoi(name); err == nil {
+		return &UserMatcher{uid: uid}, nil
+	}
+	u, err := user.Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	uid, err := strconv.Atoi(u.Uid)
+	if err != nil {
+		return nil, err
+	}
+	return &UserMatcher{uid: uid}, nil
+}
+
+// Match implements Matcher.
+func (m *UserMatcher) Match(p ProcessStat) bool {
+	return m.uid == p.UID
+}
+
+// PidfileMatcher matches the process whose ID is written in a pidfile.
+// The pidfile is read again before each collection, so restarts of the
+// process are followed.
+type PidfileMatcher struct {
+	path string
+	pid  int
+}
+
+// NewPidfileMatcher returns matcher for the process whose ID is written in the
+// pidfile at path.
+func NewPidfileMatcher(path string) *PidfileMatcher {
+	m := &PidfileMatcher{path: path}
+	m.refresh()
+	return m
+}
+
+// Match implements Matcher.
+func (m *PidfileMatcher) Match(p ProcessStat) bool {
+	return m.pid > 0 && m.pid == p.PID
+}
+
+func (m *PidfileMatcher) refresh() {
+	m.pid = 0
+	data, err := ioutil.ReadFile(m.path)
+	if err != nil {
+		return
+	}
+	if pid, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil {
+		m.pid = pid
+	}
+}
+
+// ParseGroup parses group definition in name=kind:value format, where kind is
+// one of comm, cmdline, pidfile or user. For comm and cmdline value is a
+// regular expression.
+func ParseGroup(s string) (Group, error) {
+	eq := strings.Index(s, "=")
+	if eq <= 0 {
+		return Group{}, fmt.Errorf("procstat: unsupported group format: %q", s)
+	}
+	name, spec := s[:eq], s[eq+1:]
+	colon := strings.Index(spec, ":")
+	if colon <= 0 || colon == len(spec)-1 {
+		return Group{}, fmt.Errorf("procstat: unsupported matcher format: %q", spec)
+	}
+	kind, value := spec[:colon], spec[colon+1:]
+
+	var m Matcher
+	switch kind {
+	case "comm", "cmdline":
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return Group{}, fmt.Errorf("procstat: invalid %s regexp: %v", kind, err)
+		}
+		if kind == "comm" {
+			m = NewCommMatcher(re)
+		} else {
+			m = NewCmdlineMatcher(re)
+		}
+	case "pidfile":
+		m = NewPidfileMatcher(value)
+	case "user":
+		um, err := NewUserMatcher(value)
+		if err != nil {
+			return Group{}, fmt.Errorf("procstat: unknown user %q: %v", value, err)
+		}
+		m = um
+	default:
+		return Group{}, fmt.Errorf("procstat: unsupported matcher kind: %q", kind)
+	}
+	return Group{Name: name, Matcher: m}, nil
+}
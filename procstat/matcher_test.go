@@ -0,0 +1,52 @@
+package procstat_test
+
+import (
+	"testing"
+
+	"github.com/Bo0mer/yamt/procstat"
+)
+
+func TestParseGroup(t *testing.T) {
+	postgres := procstat.ProcessStat{PID: 1234, Comm: "postgres", Cmdline: "postgres -D /data", UID: 111}
+	nginx := procstat.ProcessStat{PID: 42, Comm: "nginx", Cmdline: "nginx -g daemon off;", UID: 0}
+
+	cases := []struct {
+		spec    string
+		name    string
+		matches []procstat.ProcessStat
+		misses  []procstat.ProcessStat
+	}{
+		{"db=comm:^postgres$", "db", []procstat.ProcessStat{postgres}, []procstat.ProcessStat{nginx}},
+		{"web=cmdline:daemon off", "web", []procstat.ProcessStat{nginx}, []procstat.ProcessStat{postgres}},
+		{"root=user:0", "root", []procstat.ProcessStat{nginx}, []procstat.ProcessStat{postgres}},
+		{"db=pidfile:testdata/postgres.pid", "db", []procstat.ProcessStat{postgres}, []procstat.ProcessStat{nginx}},
+	}
+
+	for _, c := range cases {
+		g, err := procstat.ParseGroup(c.spec)
+		if err != nil {
+			t.Errorf("unexpected error parsing %q: %v\n", c.spec, err)
+			continue
+		}
+		if g.Name != c.name {
+			t.Errorf("expected group name %q, got %q\n", c.name, g.Name)
+		}
+		for _, p := range c.matches {
+			if !g.Matcher.Match(p) {
+				t.Errorf("expected %q to match %v\n", c.spec, p)
+			}
+		}
+		for _, p := range c.misses {
+			if g.Matcher.Match(p) {
+				t.Errorf("expected %q not to match %v\n", c.spec, p)
+			}
+		}
+	}
+
+	invalids := []string{"comm:x", "=comm:x", "db=comm", "db=comm:", "db=comm:(", "db=uid:0"}
+	for _, invalid := range invalids {
+		if _, err := procstat.ParseGroup(invalid); err == nil {
+			t.Errorf("expected error for %q, got nil\n", invalid)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package procstat
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Bo0mer/yamt/internal"
+	"github.com/Bo0mer/yamt/internal/hostfs"
+)
+
+//go:generate counterfeiter . ProcessStatReader
+
+// ProcessStat represents resource usage of a single process.
+type ProcessStat struct {
+	// Process ID.
+	PID int
+	// Executable name, as found in /proc/[pid]/stat.
+	Comm string
+	// Command line arguments, separated by spaces.
+	Cmdline string
+	// Real user ID of the process.
+	UID int
+	// Time the process started after system boot, in clock ticks. Together
+	// with PID it identifies a process, as PIDs may be reused.
+	StartTime uint64
+
+	// Time spent in user mode, in clock ticks.
+	UTime uint64
+	// Time spent in kernel mode, in clock ticks.
+	STime uint64
+	// Number of threads.
+	Threads uint64
+	// Resident set size in bytes.
+	RSS uint64
+	// Number of open file descriptors. Zero if not permitted to read.
+	FDs uint64
+	// Bytes read from storage. Zero if not permitted to read.
+	ReadBytes uint64
+	// Bytes written to storage. Zero if not permitted to read.
+	WriteBytes uint64
+}
+
+// ProcessStatReader should read statistics for all running processes.
+type ProcessStatReader interface {
+	ReadStats() ([]ProcessStat, error)
+}
+
+// ProcReader reads process statistics from a procfs mount.
+type ProcReader struct {
+	dir string
+}
+
+// NewProcReader creates ProcReader that reads processes from the specified
+// procfs directory. Paths under /proc are resolved relative to
+// hostfs.ProcRoot.
+func NewProcReader(dir string) *ProcReader {
+	return &ProcReader{
+		dir: dir,
+	}
+}
+
+// DefaultProcReader is the default implementation of ProcessStatReader.
+// It reads process statistics from /proc.
+var DefaultProcReader ProcessStatReader = NewProcReader("/proc")
+
+// ReadProcessStats is shorthand for DefaultProcReader.ReadStats.
+func ReadProcessStats() ([]ProcessStat, error) {
+	return DefaultProcReader.ReadStats()
+}
+
+// ReadStats reads statistics for all running processes. Processes which exit
+// while being read are skipped.
+func (r *ProcReader) ReadStats() ([]ProcessStat, error) {
+	dir := hostfs.Resolve(r.dir)
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("readprocstats: error reading from %s: %v", dir, err)
+	}
+
+	stats := make([]ProcessStat, 0, len(infos))
+	for _, info := range infos {
+		pid, err := strconv.Atoi(info.Name())
+		if err != nil || !info.IsDir() {
+			continue
+		}
+		stat, err := r.readProcess(dir, pid)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("readprocstats: error reading process %d: %v", pid, err)
+		}
+		stats = append(stats, stat)
+	}
+	return stats, nil
+}
+
+func (r *ProcReader) readProcess(procDir string, pid int) (ProcessStat, error) {
+	dir := filepath.Join(procDir, strconv.Itoa(pid))
+	stat := ProcessStat{PID: pid}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "stat"))
+	if err != nil {
+		return ProcessStat{}, err
+	}
+	if err := r.parseStat(&stat, data); err != nil {
+		return ProcessStat{}, err
+	}
+
+	data, err = ioutil.ReadFile(filepath.Join(dir, "status"))
+	if err != nil {
+		return ProcessStat{}, err
+	}
+	if err := r.parseStatus(&stat, data); err != nil {
+		return ProcessStat{}, err
+	}
+
+	data, err = ioutil.ReadFile(filepath.Join(dir, "cmdline"))
+	if err != nil {
+		return ProcessStat{}, err
+	}
+	stat.Cmdline = string(bytes.TrimSpace(bytes.Replace(data, []byte{0}, []byte{' '}, -1)))
+
+	// io and fd are readable only by the process owner, so failing to read
+	// them is not an error.
+	if data, err := ioutil.ReadFile(filepath.Join(dir, "io")); err == nil {
+		if err := r.parseIO(&stat, data); err != nil {
+			return ProcessStat{}, err
+		}
+	}
+	if fds, err := ioutil.ReadDir(filepath.Join(dir, "fd")); err == nil {
+		stat.FDs = uint64(len(fds))
+	}
+
+	return stat, nil
+}
+
+func (r *ProcReader) parseStat(stat *ProcessStat, data []byte) error {
+	line := string(data)
+	// comm may contain spaces and parentheses, hence look for the last one.
+	lparen, rparen := strings.Index(line, "("), strings.LastIndex(line, ")")
+	if lparen < 0 || rparen < lparen {
+		return fmt.Errorf("unsupported stat format: %q", line)
+	}
+	stat.Comm = line[lparen+1 : rparen]
+
+	// fields[0] is the process state, i.e. field 3 in proc(5).
+	fields := strings.Fields(line[rparen+1:])
+	if len(fields) < 20 {
+		return fmt.Errorf("unsupported stat format: %q", line)
+	}
+	p := &internal.ErrParser{}
+	stat.UTime = p.ParseUint64(fields[11])
+	stat.STime = p.ParseUint64(fields[12])
+	stat.Threads = p.ParseUint64(fields[17])
+	stat.StartTime = p.ParseUint64(fields[19])
+	return p.Err()
+}
+
+func (r *ProcReader) parseStatus(stat *ProcessStat, data []byte) error {
+	p := &internal.ErrParser{}
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "Uid:":
+			stat.UID = p.ParseInt(fields[1])
+		case "VmRSS:":
+			stat.RSS = p.ParseUint64(fields[1]) * 1024
+		}
+	}
+	return p.Err()
+}
+
+func (r *ProcReader) parseIO(stat *ProcessStat, data []byte) error {
+	p := &internal.ErrParser{}
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		switch fields[0] {
+		case "read_bytes:":
+			stat.ReadBytes = p.ParseUint64(fields[1])
+		case "write_bytes:":
+			stat.WriteBytes = p.ParseUint64(fields[1])
+		}
+	}
+	return p.Err()
+}
@@ -0,0 +1,53 @@
+package procstat_test
+
+import (
+	"testing"
+
+	"github.com/Bo0mer/yamt/procstat"
+)
+
+func TestProcReader(t *testing.T) {
+	r := procstat.NewProcReader("testdata/proc")
+	got, err := r.ReadStats()
+	if err != nil {
+		t.Errorf("unexpected error: %v\n", err)
+	}
+
+	want := []procstat.ProcessStat{
+		procstat.ProcessStat{
+			PID:        1234,
+			Comm:       "postgres: writer",
+			Cmdline:    "postgres: writer",
+			UID:        111,
+			StartTime:  33615,
+			UTime:      250,
+			STime:      120,
+			Threads:    4,
+			RSS:        20480 * 1024,
+			FDs:        3,
+			ReadBytes:  8192,
+			WriteBytes: 4096,
+		},
+		procstat.ProcessStat{
+			PID:       42,
+			Comm:      "nginx",
+			Cmdline:   "/usr/sbin/nginx -g daemon off;",
+			UID:       0,
+			StartTime: 1200,
+			UTime:     10,
+			STime:     5,
+			Threads:   1,
+			RSS:       4096 * 1024,
+			FDs:       1,
+		},
+	}
+
+	if len(want) != len(got) {
+		t.Fatalf("want %v\n\tgot %v\n", want, got)
+	}
+	for i, stat := range got {
+		if stat != want[i] {
+			t.Errorf("want %v\n\tgot %v\n", want[i], stat)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package procstat
+
+import (
+	"fmt"
+
+	"github.com/Bo0mer/yamt/metric"
+	"github.com/Bo0mer/yamt/registry"
+)
+
+func init() {
+	registry.Register(registry.Collector{
+		Name:        "process",
+		Description: "Report metrics of process groups given by -process",
+		Options: []registry.Option{
+			{Name: "process", Kind: registry.Repeated, Usage: "Process group to report, in name=kind:value format where kind is comm, cmdline, pidfile or user"},
+		},
+		EnabledBy: "process",
+		New: func(c registry.Config) ([]metric.Collector, error) {
+			specs := c.Strings("process")
+			groups := make([]Group, 0, len(specs))
+			for _, spec := range specs {
+				g, err := ParseGroup(spec)
+				if err != nil {
+					return nil, fmt.Errorf("invalid -process: %v", err)
+				}
+				groups = append(groups, g)
+			}
+			pc, err := NewProcessCollector(DefaultProcReader, groups)
+			if err != nil {
+				return nil, err
+			}
+			return []metric.Collector{metric.Named("process", pc)}, nil
+		},
+	})
+}
@@ -0,0 +1,132 @@
+package psi
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Bo0mer/yamt/internal"
+	"github.com/Bo0mer/yamt/metric"
+)
+
+type state map[string]PressureStat
+
+// PressureCollector computes metrics from pressure stall information.
+type PressureCollector struct {
+	reader      PressureStatReader
+	unsupported bool
+	last        state
+	lastTime    time.Time
+}
+
+// NewPressureCollector returns brand new pressure stall information
+// collector. Kernels without pressure stall information are not considered
+// an error; the collector logs it once and reports no events.
+func NewPressureCollector(reader PressureStatReader) (*PressureCollector, error) {
+	c := &PressureCollector{
+		reader: reader,
+	}
+	if err := c.init(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Collect collects pressure stall information and creates events for each
+// resource.
+func (c *PressureCollector) Collect() ([]metric.Event, error) {
+	if c.unsupported {
+		return nil, nil
+	}
+	actual, err := c.getState()
+	if err != nil {
+		return nil, err
+	}
+	if c.unsupported {
+		return nil, nil
+	}
+
+	actualTime := time.Now()
+	interval := actualTime.Sub(c.lastTime).Seconds()
+
+	events := make([]metric.Event, 0)
+
+	for _, resource := range Resources {
+		stat, ok := actual[resource]
+		if !ok {
+			continue
+		}
+		last, ok := c.last[resource]
+		if !ok {
+			continue
+		}
+
+		events = append(events, BuildEvents("psi", stat, last, interval)...)
+	}
+
+	c.last = actual
+	c.lastTime = actualTime
+
+	return events, nil
+}
+
+// init loads the initial state of the collector.
+func (c *PressureCollector) init() error {
+	state, err := c.getState()
+	if err != nil {
+		return err
+	}
+	c.last = state
+	c.lastTime = time.Now()
+	return nil
+}
+
+// getState reads current pressure stall information. When it turns out to be
+// unsupported, the collector is disabled and an empty state is returned.
+func (c *PressureCollector) getState() (state, error) {
+	state := make(map[string]PressureStat)
+	stats, err := c.reader.ReadStats()
+	if err == ErrUnsupported {
+		log.Printf("psi: disabling pressure collector: %v\n", err)
+		c.unsupported = true
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("collector: error reading stats: %v", err)
+	}
+	for _, stat := range stats {
+		state[stat.Resource] = stat
+	}
+	return state, nil
+}
+
+// BuildEvents builds all events for a single resource, prepending prefix
+// to their names. Stall time is reported as rate over interval seconds.
+func BuildEvents(prefix string, actual, last PressureStat, interval float64) []metric.Event {
+	events := make([]metric.Event, 0)
+	event := eventBuilder(prefix + " " + actual.Resource)
+	rate := internal.RateComputer(interval)
+
+	events = append(events, event("some avg10", actual.Some.Avg10))
+	events = append(events, event("some avg60", actual.Some.Avg60))
+	events = append(events, event("some avg300", actual.Some.Avg300))
+	events = append(events, event("some stall(us)", rate(actual.Some.Total, last.Some.Total)))
+
+	if actual.HasFull {
+		events = append(events, event("full avg10", actual.Full.Avg10))
+		events = append(events, event("full avg60", actual.Full.Avg60))
+		events = append(events, event("full avg300", actual.Full.Avg300))
+		events = append(events, event("full stall(us)", rate(actual.Full.Total, last.Full.Total)))
+	}
+
+	return events
+}
+
+func eventBuilder(prefix string) func(string, float64) metric.Event {
+	return func(name string, value float64) metric.Event {
+		return metric.Event{
+			Name:  prefix + " " + name,
+			Value: value,
+		}
+	}
+}
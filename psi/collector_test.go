@@ -0,0 +1,109 @@
+package psi_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Bo0mer/yamt/metric"
+	"github.com/Bo0mer/yamt/psi"
+	"github.com/Bo0mer/yamt/psi/psifakes"
+)
+
+// Test that *PressureCollector implements metric.Collector
+var _ metric.Collector = (*psi.PressureCollector)(nil)
+
+func TestNewPressureCollector(t *testing.T) {
+	errReader := new(psifakes.FakePressureStatReader)
+	errReader.ReadStatsReturns(nil, errors.New("kaboom"))
+	_, err := psi.NewPressureCollector(errReader)
+	if err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+var stats = map[int][]psi.PressureStat{
+	0: []psi.PressureStat{
+		psi.PressureStat{
+			Resource: "cpu",
+			Some:     psi.Pressure{Avg10: 1.5, Total: 1000},
+		},
+	},
+	1: []psi.PressureStat{
+		psi.PressureStat{
+			Resource: "cpu",
+			Some:     psi.Pressure{Avg10: 2.5, Total: 2000},
+		},
+	},
+}
+
+func newFakedReader(t *testing.T) psi.PressureStatReader {
+	r := new(psifakes.FakePressureStatReader)
+	i := 0
+	r.ReadStatsStub = func() ([]psi.PressureStat, error) {
+		ret := stats[i]
+		i++
+		return ret, nil
+	}
+	return r
+}
+
+func TestPressureCollectorCollect(t *testing.T) {
+	want := []metric.Event{
+		metric.Event{Name: "psi cpu some avg10", Value: 2.5},
+		metric.Event{Name: "psi cpu some avg60", Value: 0.0},
+		metric.Event{Name: "psi cpu some avg300", Value: 0.0},
+		metric.Event{}, // some stall(us), handled separately
+	}
+
+	reader := newFakedReader(t)
+	c, err := psi.NewPressureCollector(reader)
+	if err != nil {
+		t.Errorf("unexpected error: %v\n", err)
+	}
+	got, err := c.Collect()
+	if err != nil {
+		t.Errorf("unexpected error: %v\n", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d events, got %d\n", len(want), len(got))
+	}
+
+	for i := range got {
+		if got[i].Name == "psi cpu some stall(us)" {
+			if f, ok := got[i].Value.(float64); !ok {
+				t.Errorf("expected float64 value, got %T\n", got[i].Value)
+			} else {
+				if f <= 0 {
+					t.Errorf("expected positive value, got %f\n", f)
+				}
+			}
+			continue
+		}
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("expected %#v, got %#v\n", want[i], got[i])
+		}
+	}
+}
+
+func TestPressureCollectorCollect_unsupported(t *testing.T) {
+	reader := new(psifakes.FakePressureStatReader)
+	reader.ReadStatsReturns(nil, psi.ErrUnsupported)
+	c, err := psi.NewPressureCollector(reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		got, err := c.Collect()
+		if err != nil {
+			t.Errorf("unexpected error: %v\n", err)
+		}
+		if len(got) != 0 {
+			t.Errorf("expected zero results, got %v\n", got)
+		}
+	}
+	if n := reader.ReadStatsCallCount(); n != 1 {
+		t.Errorf("expected single read, got %d\n", n)
+	}
+}
@@ -0,0 +1,169 @@
+package psi
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/Bo0mer/yamt/internal"
+	"github.com/Bo0mer/yamt/internal/hostfs"
+)
+
+//go:generate counterfeiter . PressureStatReader
+
+// ErrUnsupported is returned by readers when the running kernel does not
+// expose pressure stall information, e.g. it is built without CONFIG_PSI or
+// booted with psi=0.
+var ErrUnsupported = errors.New("pressure stall information not supported")
+
+// Resources lists the resources for which pressure stall information is read.
+var Resources = []string{"cpu", "memory", "io"}
+
+// Pressure represents a single line of a pressure file.
+type Pressure struct {
+	// Share of time in percents some (or all) tasks were stalled, averaged
+	// over the last 10, 60 and 300 seconds.
+	Avg10  float64
+	Avg60  float64
+	Avg300 float64
+	// Total stall time in microseconds.
+	Total uint64
+}
+
+// PressureStat represents pressure stall information for a single resource.
+type PressureStat struct {
+	// Resource name, one of Resources.
+	Resource string
+	// Time in which at least some tasks were stalled on the resource.
+	Some Pressure
+	// Time in which all non-idle tasks were stalled on the resource.
+	Full Pressure
+	// HasFull is false when the kernel does not report the full line for
+	// the resource, which is the case for cpu on older kernels.
+	HasFull bool
+}
+
+// PressureStatReader should read pressure stall information for all
+// available resources.
+type PressureStatReader interface {
+	ReadStats() ([]PressureStat, error)
+}
+
+// PSIReader reads pressure stall information.
+type PSIReader struct {
+	dir string
+}
+
+// NewPSIReader creates PSIReader that reads pressure files from the specified
+// directory. Paths under /proc are resolved relative to hostfs.ProcRoot.
+func NewPSIReader(dir string) *PSIReader {
+	return &PSIReader{
+		dir: dir,
+	}
+}
+
+// DefaultPSIReader is the default implementation of PressureStatReader.
+// It reads pressure stall information from /proc/pressure.
+var DefaultPSIReader PressureStatReader = NewPSIReader("/proc/pressure")
+
+// ReadPressureStats is shorthand for DefaultPSIReader.ReadStats.
+func ReadPressureStats() ([]PressureStat, error) {
+	return DefaultPSIReader.ReadStats()
+}
+
+// ReadStats reads pressure stall information for all resources. Resources
+// without a pressure file are skipped. If none is available, ErrUnsupported
+// is returned.
+func (r *PSIReader) ReadStats() ([]PressureStat, error) {
+	stats := make([]PressureStat, 0, len(Resources))
+	for _, resource := range Resources {
+		path := filepath.Join(hostfs.Resolve(r.dir), resource)
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			if isUnsupported(err) {
+				continue
+			}
+			return nil, fmt.Errorf("readpsi: error reading from %s: %v", path, err)
+		}
+		stat, err := ParseStat(resource, data)
+		if err != nil {
+			return nil, fmt.Errorf("readpsi: error parsing %s: %v", path, err)
+		}
+		stats = append(stats, stat)
+	}
+	if len(stats) == 0 {
+		return nil, ErrUnsupported
+	}
+	return stats, nil
+}
+
+// ParseStat parses contents of a pressure file for the specified resource.
+// Besides /proc/pressure, files in this format are found in cgroup v2
+// directories, e.g. cpu.pressure.
+func ParseStat(resource string, data []byte) (PressureStat, error) {
+	stat := PressureStat{Resource: resource}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 5 {
+			return PressureStat{}, fmt.Errorf("unsupported format: %q", line)
+		}
+		pressure, err := parsePressure(fields[1:])
+		if err != nil {
+			return PressureStat{}, err
+		}
+		switch fields[0] {
+		case "some":
+			stat.Some = pressure
+		case "full":
+			stat.Full = pressure
+			stat.HasFull = true
+		default:
+			return PressureStat{}, fmt.Errorf("unsupported format: %q", line)
+		}
+	}
+	return stat, nil
+}
+
+func parsePressure(fields []string) (Pressure, error) {
+	p := &internal.ErrParser{}
+	pressure := Pressure{}
+	for _, field := range fields {
+		kv := strings.SplitN(field, "=", 2)
+		if len(kv) != 2 {
+			return Pressure{}, fmt.Errorf("unsupported field format: %q", field)
+		}
+		switch kv[0] {
+		case "avg10":
+			pressure.Avg10 = p.ParseFloat64(kv[1])
+		case "avg60":
+			pressure.Avg60 = p.ParseFloat64(kv[1])
+		case "avg300":
+			pressure.Avg300 = p.ParseFloat64(kv[1])
+		case "total":
+			pressure.Total = p.ParseUint64(kv[1])
+		}
+	}
+	if err := p.Err(); err != nil {
+		return Pressure{}, err
+	}
+	return pressure, nil
+}
+
+// isUnsupported reports whether err indicates that the kernel does not
+// provide the pressure file at all.
+func isUnsupported(err error) bool {
+	if os.IsNotExist(err) {
+		return true
+	}
+	if pathErr, ok := err.(*os.PathError); ok {
+		return pathErr.Err == syscall.EOPNOTSUPP
+	}
+	return false
+}
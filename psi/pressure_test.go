@@ -0,0 +1,75 @@
+package psi_test
+
+import (
+	"testing"
+
+	"github.com/Bo0mer/yamt/psi"
+)
+
+func TestPSIReader(t *testing.T) {
+	r := psi.NewPSIReader("testdata/pressure")
+	got, err := r.ReadStats()
+	if err != nil {
+		t.Errorf("unexpected error: %v\n", err)
+	}
+
+	want := []psi.PressureStat{
+		psi.PressureStat{
+			Resource: "cpu",
+			Some:     psi.Pressure{Avg10: 1.53, Avg60: 0.87, Avg300: 0.25, Total: 117284},
+		},
+		psi.PressureStat{
+			Resource: "memory",
+			Some:     psi.Pressure{Avg10: 0.00, Avg60: 0.12, Avg300: 0.03, Total: 4210},
+			Full:     psi.Pressure{Avg10: 0.00, Avg60: 0.05, Avg300: 0.01, Total: 2105},
+			HasFull:  true,
+		},
+		psi.PressureStat{
+			Resource: "io",
+			Some:     psi.Pressure{Avg10: 12.40, Avg60: 8.21, Avg300: 3.97, Total: 98457120},
+			Full:     psi.Pressure{Avg10: 10.02, Avg60: 6.60, Avg300: 3.01, Total: 80311455},
+			HasFull:  true,
+		},
+	}
+
+	if len(want) != len(got) {
+		t.Fatalf("want %v\n\tgot %v\n", want, got)
+	}
+	for i, stat := range got {
+		if stat != want[i] {
+			t.Errorf("want %v\n\tgot %v\n", want[i], stat)
+		}
+	}
+}
+
+func TestPSIReader_unsupported(t *testing.T) {
+	r := psi.NewPSIReader("testdata/missing")
+	_, err := r.ReadStats()
+	if err != psi.ErrUnsupported {
+		t.Errorf("expected %v, got %v\n", psi.ErrUnsupported, err)
+	}
+}
+
+func TestParseStat(t *testing.T) {
+	data := []byte("some avg10=1.53 avg60=0.87 avg300=0.25 total=117284\n  \n")
+	got, err := psi.ParseStat("cpu", data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	want := psi.PressureStat{
+		Resource: "cpu",
+		Some:     psi.Pressure{Avg10: 1.53, Avg60: 0.87, Avg300: 0.25, Total: 117284},
+	}
+	if got != want {
+		t.Errorf("want %v\n\tgot %v\n", want, got)
+	}
+
+	for _, data := range []string{
+		"some\n",
+		"some avg10=1.53 avg60=0.87 avg300=0.25 total=117284\nfull avg10=0.00 avg\n",
+	} {
+		if _, err := psi.ParseStat("memory", []byte(data)); err == nil {
+			t.Errorf("expected error parsing %q\n", data)
+		}
+	}
+}
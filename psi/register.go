@@ -0,0 +1,20 @@
+package psi
+
+import (
+	"github.com/Bo0mer/yamt/metric"
+	"github.com/Bo0mer/yamt/registry"
+)
+
+func init() {
+	registry.Register(registry.Collector{
+		Name:        "psi",
+		Description: "Report pressure stall information",
+		New: func(c registry.Config) ([]metric.Collector, error) {
+			pc, err := NewPressureCollector(DefaultPSIReader)
+			if err != nil {
+				return nil, err
+			}
+			return []metric.Collector{metric.Named("psi", pc)}, nil
+		},
+	})
+}
@@ -0,0 +1,282 @@
+// Package registry keeps track of the available collectors. Collector
+// packages register themselves on init, describing their options, which the
+// registry exposes as command line flags.
+package registry
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Bo0mer/yamt/internal/flagvar"
+	"github.com/Bo0mer/yamt/metric"
+)
+
+// Kind is the type of an option value.
+type Kind int
+
+// Supported option kinds.
+const (
+	String Kind = iota
+	Bool
+	Int
+	Duration
+	// Repeated options may be given more than once, collecting all values.
+	Repeated
+)
+
+// Option describes an option of a collector, exposed as a command line
+// flag. Options with the same name are shared between collectors.
+type Option struct {
+	Name string
+	// Short is an optional shorthand flag name.
+	Short   string
+	Kind    Kind
+	Usage   string
+	Default string
+}
+
+// DeviceExpiry is the option shared by collectors of devices which may come
+// and go, e.g. disks and network interfaces.
+var DeviceExpiry = Option{
+	Name:  "device-expiry",
+	Kind:  Duration,
+	Usage: "Report disks and interfaces which appear or disappear, expiring metrics of those gone this long, disabled if zero",
+}
+
+// Factory creates collectors using the configured options.
+type Factory func(c Config) ([]metric.Collector, error)
+
+// Collector describes a collector.
+type Collector struct {
+	// Name of the collector, also the name of the flag enabling it.
+	Name        string
+	Description string
+	Options     []Option
+	// EnabledBy is the name of a repeated option which enables the
+	// collector when given, instead of a flag named after the collector.
+	EnabledBy string
+	// Sampled marks collectors worth sampling more often than they are
+	// reported, see metric.Sampled.
+	Sampled bool
+	New     Factory
+}
+
+var (
+	mu         sync.Mutex
+	collectors = make(map[string]Collector)
+)
+
+// Register makes collector available by its name. It panics if the name is
+// registered twice.
+func Register(c Collector) {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := collectors[c.Name]; ok {
+		panic("registry: collector " + c.Name + " registered twice")
+	}
+	collectors[c.Name] = c
+}
+
+// Lookup returns the named collector.
+func Lookup(name string) (Collector, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	c, ok := collectors[name]
+	return c, ok
+}
+
+// Collectors returns all registered collectors sorted by name.
+func Collectors() []Collector {
+	mu.Lock()
+	defer mu.Unlock()
+	names := make([]string, 0, len(collectors))
+	for name := range collectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	all := make([]Collector, 0, len(names))
+	for _, name := range names {
+		all = append(all, collectors[name])
+	}
+	return all
+}
+
+// Flags defines flags of the registered collectors in fs: a flag enabling
+// each collector, and a flag for each option. Collectors enabled by the
+// flags are reported by Enabled, their options are available in Config.
+func Flags(fs *flag.FlagSet) *Config {
+	c := &Config{
+		values:  make(map[string]flag.Value),
+		enabled: make(map[string]*bool),
+	}
+	for _, col := range Collectors() {
+		if col.EnabledBy == "" {
+			c.enabled[col.Name] = fs.Bool(col.Name, false, col.Description)
+		}
+		for _, opt := range col.Options {
+			if _, ok := c.values[opt.Name]; ok {
+				continue
+			}
+			c.values[opt.Name] = define(fs, opt)
+		}
+	}
+	return c
+}
+
+// define defines flag of the option, and its shorthand, in fs.
+func define(fs *flag.FlagSet, opt Option) flag.Value {
+	names := []string{opt.Name}
+	if opt.Short != "" {
+		names = append(names, opt.Short)
+	}
+	var v flag.Value
+	for i, name := range names {
+		usage := opt.Usage
+		if i > 0 {
+			usage += " (shorthand)"
+		}
+		if v != nil {
+			fs.Var(v, name, usage)
+			continue
+		}
+		switch opt.Kind {
+		case String:
+			fs.String(name, opt.Default, usage)
+		case Bool:
+			b, _ := strconv.ParseBool(opt.Default)
+			fs.Bool(name, b, usage)
+		case Int:
+			n, _ := strconv.Atoi(opt.Default)
+			fs.Int(name, n, usage)
+		case Duration:
+			d, _ := time.ParseDuration(opt.Default)
+			fs.Duration(name, d, usage)
+		case Repeated:
+			fs.Var(new(flagvar.Array), name, usage)
+		}
+		v = fs.Lookup(name).Value
+	}
+	return v
+}
+
+// Config holds option values of collectors.
+type Config struct {
+	values  map[string]flag.Value
+	enabled map[string]*bool
+}
+
+// Enabled returns names of the collectors enabled by flags, sorted.
+func (c *Config) Enabled() []string {
+	names := make([]string, 0)
+	for _, col := range Collectors() {
+		if col.EnabledBy != "" {
+			if len(c.Strings(col.EnabledBy)) > 0 {
+				names = append(names, col.Name)
+			}
+			continue
+		}
+		if enabled := c.enabled[col.Name]; enabled != nil && *enabled {
+			names = append(names, col.Name)
+		}
+	}
+	return names
+}
+
+// New creates the named collector.
+func (c *Config) New(name string) ([]metric.Collector, error) {
+	col, ok := Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("registry: unknown collector %q", name)
+	}
+	created, err := col.New(*c)
+	if err != nil {
+		return nil, fmt.Errorf("registry: error creating %s collector: %v", name, err)
+	}
+	return created, nil
+}
+
+// String returns value of the named option.
+func (c Config) String(name string) string {
+	v, ok := c.values[name]
+	if !ok {
+		return ""
+	}
+	return v.String()
+}
+
+// Strings returns all values of the named repeated option.
+func (c Config) Strings(name string) []string {
+	if a, ok := c.values[name].(*flagvar.Array); ok {
+		return *a
+	}
+	return nil
+}
+
+// Bool returns value of the named boolean option.
+func (c Config) Bool(name string) (bool, error) {
+	s := c.String(name)
+	if s == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, fmt.Errorf("invalid -%s: %v", name, err)
+	}
+	return b, nil
+}
+
+// Int returns value of the named integer option.
+func (c Config) Int(name string) (int, error) {
+	i, err := strconv.Atoi(c.String(name))
+	if err != nil {
+		return 0, fmt.Errorf("invalid -%s: %v", name, err)
+	}
+	return i, nil
+}
+
+// Duration returns value of the named duration option.
+func (c Config) Duration(name string) (time.Duration, error) {
+	d, err := time.ParseDuration(c.String(name))
+	if err != nil {
+		return 0, fmt.Errorf("invalid -%s: %v", name, err)
+	}
+	return d, nil
+}
+
+// Regexp returns the compiled value of the named option, or nil if it is
+// empty.
+func (c Config) Regexp(name string) (*regexp.Regexp, error) {
+	s := c.String(name)
+	if s == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -%s: %v", name, err)
+	}
+	return re, nil
+}
+
+// List writes the registered collectors with their options to w, marking
+// those which can be sampled.
+func List(w io.Writer) {
+	for _, col := range Collectors() {
+		description := col.Description
+		if col.Sampled {
+			description += " (sampled with -sample-interval)"
+		}
+		fmt.Fprintf(w, "%s\n    \t%s\n", col.Name, description)
+		fs := flag.NewFlagSet(col.Name, flag.ContinueOnError)
+		fs.SetOutput(w)
+		for _, opt := range col.Options {
+			define(fs, opt)
+		}
+		fs.PrintDefaults()
+	}
+}
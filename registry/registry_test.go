@@ -0,0 +1,137 @@
+package registry_test
+
+import (
+	"bytes"
+	"flag"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Bo0mer/yamt/metric"
+	"github.com/Bo0mer/yamt/metric/metricfakes"
+	"github.com/Bo0mer/yamt/registry"
+)
+
+type options struct {
+	name     string
+	total    bool
+	depth    int
+	timeout  time.Duration
+	groups   []string
+	matching bool
+}
+
+var got options
+
+func init() {
+	registry.Register(registry.Collector{
+		Name:        "test",
+		Description: "Report test metrics",
+		Sampled:     true,
+		Options: []registry.Option{
+			{Name: "test-name", Short: "n", Usage: "Name", Default: "x"},
+			{Name: "test-total", Kind: registry.Bool, Usage: "Total"},
+			{Name: "test-depth", Kind: registry.Int, Usage: "Depth", Default: "2"},
+			{Name: "test-timeout", Kind: registry.Duration, Usage: "Timeout", Default: "1s"},
+			{Name: "test-group", Kind: registry.Repeated, Usage: "Group"},
+			{Name: "test-match", Usage: "Match"},
+		},
+		New: func(c registry.Config) ([]metric.Collector, error) {
+			var err error
+			got.name = c.String("test-name")
+			if got.total, err = c.Bool("test-total"); err != nil {
+				return nil, err
+			}
+			if got.depth, err = c.Int("test-depth"); err != nil {
+				return nil, err
+			}
+			if got.timeout, err = c.Duration("test-timeout"); err != nil {
+				return nil, err
+			}
+			got.groups = c.Strings("test-group")
+			match, err := c.Regexp("test-match")
+			if err != nil {
+				return nil, err
+			}
+			got.matching = match != nil && match.MatchString("sda")
+			return []metric.Collector{new(metricfakes.FakeCollector)}, nil
+		},
+	})
+	registry.Register(registry.Collector{
+		Name:        "test-repeated",
+		Description: "Report test metrics of groups",
+		Options:     []registry.Option{{Name: "test-group", Kind: registry.Repeated, Usage: "Group"}},
+		EnabledBy:   "test-group",
+		New: func(c registry.Config) ([]metric.Collector, error) {
+			return nil, nil
+		},
+	})
+}
+
+func TestFlags(t *testing.T) {
+	fs := flag.NewFlagSet("yamt", flag.ContinueOnError)
+	config := registry.Flags(fs)
+	if got := config.Enabled(); len(got) != 0 {
+		t.Errorf("expected no enabled collectors, got %v\n", got)
+	}
+
+	err := fs.Parse([]string{"-test", "-n", "y", "-test-total", "-test-depth", "3", "-test-timeout", "2s",
+		"-test-group", "a", "-test-group", "b", "-test-match", "^sd"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	if want := []string{"test", "test-repeated"}; !reflect.DeepEqual(config.Enabled(), want) {
+		t.Errorf("expected %v to be enabled, got %v\n", want, config.Enabled())
+	}
+
+	collectors, err := config.New("test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	if len(collectors) != 1 {
+		t.Errorf("expected one collector, got %d\n", len(collectors))
+	}
+	want := options{name: "y", total: true, depth: 3, timeout: 2 * time.Second, groups: []string{"a", "b"}, matching: true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected options %+v, got %+v\n", want, got)
+	}
+
+	if _, err := config.New("unknown"); err == nil {
+		t.Error("expected error creating unknown collector")
+	}
+}
+
+func TestFlags_defaults(t *testing.T) {
+	fs := flag.NewFlagSet("yamt", flag.ContinueOnError)
+	config := registry.Flags(fs)
+	if err := fs.Parse(nil); err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	if _, err := config.New("test"); err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	want := options{name: "x", depth: 2, timeout: time.Second}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected options %+v, got %+v\n", want, got)
+	}
+}
+
+func TestRegister_twice(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected registering a collector twice to panic")
+		}
+	}()
+	registry.Register(registry.Collector{Name: "test"})
+}
+
+func TestList(t *testing.T) {
+	var buf bytes.Buffer
+	registry.List(&buf)
+	for _, s := range []string{"test\n", "Report test metrics (sampled with -sample-interval)", "-test-depth int", "(default 2)"} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("expected list to contain %q, got\n%s", s, buf.String())
+		}
+	}
+}
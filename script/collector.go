@@ -0,0 +1,128 @@
+package script
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/Bo0mer/yamt/metric"
+)
+
+// CommandCollector runs external commands and creates events from their
+// output.
+type CommandCollector struct {
+	runner      Runner
+	commands    []Command
+	concurrency int
+}
+
+// NewCommandCollector returns brand new command collector. At most
+// concurrency commands run at the same time.
+func NewCommandCollector(runner Runner, commands []Command, concurrency int) (*CommandCollector, error) {
+	if concurrency <= 0 {
+		return nil, errors.New("script: concurrency must be positive")
+	}
+	return &CommandCollector{
+		runner:      runner,
+		commands:    commands,
+		concurrency: concurrency,
+	}, nil
+}
+
+// Collect runs all commands and creates events from their output, in the
+// order commands were configured. A command which fails to run or produces
+// malformed output is reported by a critical status event, so that one
+// broken command does not hide the results of others.
+func (c *CommandCollector) Collect() ([]metric.Event, error) {
+	return c.CollectContext(context.Background())
+}
+
+// deadlineMargin is the longest time reserved before the collection
+// deadline for killing commands still running and returning the results of
+// the others, so that they are not dropped along with the collection.
+const deadlineMargin = 100 * time.Millisecond
+
+// CollectContext is like Collect, but commands which have not been started
+// when ctx is done are not run, and the running ones are killed shortly
+// before its deadline. Both are reported as failed, while events of the
+// commands which finished are returned.
+func (c *CommandCollector) CollectContext(ctx context.Context) ([]metric.Event, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		margin := deadline.Sub(time.Now()) / 10
+		if margin > deadlineMargin {
+			margin = deadlineMargin
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline.Add(-margin))
+		defer cancel()
+	}
+
+	results := make([][]metric.Event, len(c.commands))
+	sem := make(chan struct{}, c.concurrency)
+	var wg sync.WaitGroup
+	for i, cmd := range c.commands {
+		if err := ctx.Err(); err != nil {
+			results[i] = []metric.Event{failure(cmd, unknownExitCode, err)}
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			results[i] = []metric.Event{failure(cmd, unknownExitCode, ctx.Err())}
+			continue
+		}
+		wg.Add(1)
+		go func(i int, cmd Command) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = c.run(ctx, cmd)
+		}(i, cmd)
+	}
+	wg.Wait()
+
+	events := make([]metric.Event, 0)
+	for _, r := range results {
+		events = append(events, r...)
+	}
+	return events, nil
+}
+
+// run runs a single command and creates events from its output.
+func (c *CommandCollector) run(ctx context.Context, cmd Command) []metric.Event {
+	result, err := c.runner.Run(ctx, cmd)
+	if err != nil {
+		return []metric.Event{failure(cmd, unknownExitCode, err)}
+	}
+
+	if cmd.Format == FormatNagios {
+		return ParseNagios(cmd.Name, result.Output, result.ExitCode)
+	}
+
+	events, err := ParseLines(cmd.Name, result.Output)
+	if err != nil {
+		return []metric.Event{failure(cmd, unknownExitCode, err)}
+	}
+	if result.ExitCode != 0 {
+		err := errors.New("script: command exited with non zero code")
+		events = append(events, failure(cmd, result.ExitCode, err))
+	}
+	return events
+}
+
+// unknownExitCode is the Nagios exit code for unknown state, reported for
+// commands which could not be run.
+const unknownExitCode = 3
+
+// failure returns status event for a command which did not succeed.
+func failure(cmd Command, exitCode int, err error) metric.Event {
+	return metric.Event{
+		Name:  cmd.Name + " status",
+		Value: float64(exitCode),
+		State: metric.StateCritical,
+		Attributes: map[string]string{
+			"command": cmd.Name,
+			"error":   err.Error(),
+		},
+	}
+}
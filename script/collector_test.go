@@ -0,0 +1,178 @@
+package script_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Bo0mer/yamt/metric"
+	"github.com/Bo0mer/yamt/script"
+	"github.com/Bo0mer/yamt/script/scriptfakes"
+)
+
+// Test that *CommandCollector implements metric.ContextCollector
+var _ metric.ContextCollector = (*script.CommandCollector)(nil)
+
+func TestNewCommandCollector(t *testing.T) {
+	_, err := script.NewCommandCollector(new(scriptfakes.FakeRunner), nil, 0)
+	if err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+func TestCommandCollectorCollect(t *testing.T) {
+	runner := new(scriptfakes.FakeRunner)
+	runner.RunStub = func(ctx context.Context, cmd script.Command) (script.Result, error) {
+		switch cmd.Name {
+		case "ping":
+			return script.Result{Output: []byte("PING CRITICAL | rta=500ms;100;200"), ExitCode: 2}, nil
+		case "app":
+			return script.Result{Output: []byte("queue 5\n")}, nil
+		}
+		return script.Result{}, errors.New("kaboom")
+	}
+	commands := []script.Command{
+		script.Command{Name: "ping", Format: script.FormatNagios},
+		script.Command{Name: "app", Format: script.FormatLines},
+		script.Command{Name: "broken", Format: script.FormatLines},
+	}
+
+	c, err := script.NewCommandCollector(runner, commands, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	got, err := c.Collect()
+	if err != nil {
+		t.Errorf("unexpected error: %v\n", err)
+	}
+
+	want := []metric.Event{
+		metric.Event{
+			Name:       "ping status",
+			Value:      2.0,
+			State:      metric.StateCritical,
+			Attributes: map[string]string{"command": "ping", "output": "PING CRITICAL"},
+		},
+		metric.Event{Name: "ping rta(ms)", Value: 500.0, State: metric.StateCritical, Attributes: map[string]string{"command": "ping"}},
+		metric.Event{Name: "app queue", Value: 5.0, Attributes: map[string]string{"command": "app"}},
+		metric.Event{
+			Name:       "broken status",
+			Value:      3.0,
+			State:      metric.StateCritical,
+			Attributes: map[string]string{"command": "broken", "error": "kaboom"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %#v, got %#v\n", want, got)
+	}
+}
+
+func TestCommandCollectorCollect_concurrency(t *testing.T) {
+	var mu sync.Mutex
+	running, max := 0, 0
+	runner := new(scriptfakes.FakeRunner)
+	runner.RunStub = func(ctx context.Context, cmd script.Command) (script.Result, error) {
+		mu.Lock()
+		running++
+		if running > max {
+			max = running
+		}
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return script.Result{}, nil
+	}
+	commands := make([]script.Command, 10)
+	for i := range commands {
+		commands[i] = script.Command{Name: "c", Format: script.FormatLines}
+	}
+
+	c, err := script.NewCommandCollector(runner, commands, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	if _, err := c.Collect(); err != nil {
+		t.Errorf("unexpected error: %v\n", err)
+	}
+	if runner.RunCallCount() != 10 {
+		t.Errorf("want 10 runs, got %d\n", runner.RunCallCount())
+	}
+	if max > 3 {
+		t.Errorf("want at most 3 concurrent runs, got %d\n", max)
+	}
+}
+
+func TestCommandCollectorCollectContext(t *testing.T) {
+	runner := new(scriptfakes.FakeRunner)
+	runner.RunReturns(script.Result{}, nil)
+	commands := []script.Command{
+		script.Command{Name: "skipped", Format: script.FormatLines},
+	}
+
+	c, err := script.NewCommandCollector(runner, commands, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	got, err := c.CollectContext(ctx)
+	if err != nil {
+		t.Errorf("unexpected error: %v\n", err)
+	}
+
+	if runner.RunCallCount() != 0 {
+		t.Errorf("expected no commands to run, got %d\n", runner.RunCallCount())
+	}
+	if len(got) != 1 || got[0].Name != "skipped status" || got[0].State != metric.StateCritical {
+		t.Errorf("expected critical status event, got %v\n", got)
+	}
+}
+
+func TestCommandCollectorCollectContext_deadline(t *testing.T) {
+	runner := new(scriptfakes.FakeRunner)
+	runner.RunStub = func(ctx context.Context, cmd script.Command) (script.Result, error) {
+		if cmd.Name == "slow" {
+			<-ctx.Done()
+			return script.Result{}, script.ErrTimeout
+		}
+		return script.Result{Output: []byte("queue 5\n")}, nil
+	}
+	commands := []script.Command{
+		script.Command{Name: "slow", Format: script.FormatNagios},
+		script.Command{Name: "app", Format: script.FormatLines},
+	}
+
+	c, err := script.NewCommandCollector(runner, commands, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	got, err := c.CollectContext(ctx)
+	if err != nil {
+		t.Errorf("unexpected error: %v\n", err)
+	}
+	// Slow commands are killed before the deadline, so that the events of
+	// the others make it in time.
+	if ctx.Err() != nil {
+		t.Error("expected collection to finish before the deadline")
+	}
+
+	want := []metric.Event{
+		metric.Event{
+			Name:       "slow status",
+			Value:      3.0,
+			State:      metric.StateCritical,
+			Attributes: map[string]string{"command": "slow", "error": script.ErrTimeout.Error()},
+		},
+		metric.Event{Name: "app queue", Value: 5.0, Attributes: map[string]string{"command": "app"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %#v, got %#v\n", want, got)
+	}
+}
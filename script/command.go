@@ -0,0 +1,145 @@
+package script
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+)
+
+//go:generate counterfeiter . Runner
+
+// Format describes how command output is turned into events.
+type Format string
+
+const (
+	// FormatNagios parses Nagios plugin output. The exit code sets the
+	// state and performance data is reported as events.
+	FormatNagios Format = "nagios"
+	// FormatLines parses lines in "name value [state]" format.
+	FormatLines Format = "lines"
+)
+
+// Command is an external command run on each collection.
+type Command struct {
+	// Name is prepended to the names of all events created from the output.
+	Name string
+	// Format of the command output.
+	Format Format
+	// Command line, run by /bin/sh.
+	Line string
+}
+
+// ParseCommand parses command in name=format:command line format, e.g.
+// disk=nagios:/usr/lib/nagios/plugins/check_disk -w 20% -c 10% -p /
+func ParseCommand(s string) (Command, error) {
+	eq := strings.Index(s, "=")
+	if eq <= 0 {
+		return Command{}, fmt.Errorf("script: unsupported command format: %q", s)
+	}
+	name, spec := s[:eq], s[eq+1:]
+	colon := strings.Index(spec, ":")
+	if colon <= 0 || colon == len(spec)-1 {
+		return Command{}, fmt.Errorf("script: unsupported command format: %q", spec)
+	}
+	format, line := Format(spec[:colon]), spec[colon+1:]
+
+	switch format {
+	case FormatNagios, FormatLines:
+	default:
+		return Command{}, fmt.Errorf("script: unsupported output format: %q", format)
+	}
+	return Command{Name: name, Format: format, Line: line}, nil
+}
+
+// Result is the outcome of a finished command.
+type Result struct {
+	// Standard output of the command.
+	Output []byte
+	// Exit code of the command.
+	ExitCode int
+}
+
+// Runner should run the specified command and wait for it to finish, or
+// kill it when ctx is done. A non zero exit code is not an error.
+type Runner interface {
+	Run(ctx context.Context, cmd Command) (Result, error)
+}
+
+// ErrTimeout is returned by ExecRunner when a command does not finish in
+// time, either within the runner timeout or before the context deadline.
+var ErrTimeout = errors.New("script: command timed out")
+
+// ExecRunner runs commands as child processes.
+type ExecRunner struct {
+	timeout time.Duration
+}
+
+// NewExecRunner creates ExecRunner that kills commands running longer than
+// the specified timeout, or past the deadline of the context they are run
+// with, along with all processes they started.
+func NewExecRunner(timeout time.Duration) *ExecRunner {
+	return &ExecRunner{
+		timeout: timeout,
+	}
+}
+
+// Run runs the specified command. Each command runs in its own process
+// group, so that the whole group can be killed once ctx is done or the
+// runner timeout expires.
+func (r *ExecRunner) Run(ctx context.Context, cmd Command) (Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	c := exec.CommandContext(ctx, "/bin/sh", "-c", cmd.Line)
+	c.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	var out bytes.Buffer
+	c.Stdout = &out
+
+	if err := c.Start(); err != nil {
+		return Result{}, fmt.Errorf("script: error starting %s: %v", cmd.Name, err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Wait()
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		// CommandContext kills only the shell, processes it started may
+		// keep stdout open. Negative pid signals the whole process group.
+		syscall.Kill(-c.Process.Pid, syscall.SIGKILL)
+		err = <-done
+	}
+	if ctx.Err() != nil && err != nil {
+		// Most likely killed above or by CommandContext.
+		syscall.Kill(-c.Process.Pid, syscall.SIGKILL)
+		if ctx.Err() == context.DeadlineExceeded {
+			return Result{}, ErrTimeout
+		}
+		return Result{}, fmt.Errorf("script: %s cancelled: %v", cmd.Name, ctx.Err())
+	}
+
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return Result{Output: out.Bytes(), ExitCode: exitCode(exitErr)}, nil
+	}
+	if err != nil {
+		return Result{}, fmt.Errorf("script: error running %s: %v", cmd.Name, err)
+	}
+	return Result{Output: out.Bytes()}, nil
+}
+
+// exitCode returns exit code of the exited command.
+func exitCode(err *exec.ExitError) int {
+	if status, ok := err.Sys().(syscall.WaitStatus); ok {
+		return status.ExitStatus()
+	}
+	return unknownExitCode
+}
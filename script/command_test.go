@@ -0,0 +1,67 @@
+package script_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Bo0mer/yamt/script"
+)
+
+func TestParseCommand(t *testing.T) {
+	got, err := script.ParseCommand("disk=nagios:check_disk -w 20% -c 10%")
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	want := script.Command{Name: "disk", Format: script.FormatNagios, Line: "check_disk -w 20% -c 10%"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v\n", want, got)
+	}
+
+	for _, bad := range []string{"disk", "=nagios:true", "disk=nagios", "disk=nagios:", "disk=json:true"} {
+		if _, err := script.ParseCommand(bad); err == nil {
+			t.Errorf("%q: expected error, got nil\n", bad)
+		}
+	}
+}
+
+func TestExecRunner(t *testing.T) {
+	r := script.NewExecRunner(5 * time.Second)
+	got, err := r.Run(context.Background(), script.Command{Name: "test", Line: "echo 'OK | v=1'; exit 2"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	want := script.Result{Output: []byte("OK | v=1\n"), ExitCode: 2}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %#v, got %#v\n", want, got)
+	}
+}
+
+func TestExecRunner_timeout(t *testing.T) {
+	r := script.NewExecRunner(100 * time.Millisecond)
+	start := time.Now()
+	// The background sleep keeps stdout open, so the whole process group
+	// has to be killed for Run to return.
+	_, err := r.Run(context.Background(), script.Command{Name: "test", Line: "sleep 10 & sleep 10"})
+	if err != script.ErrTimeout {
+		t.Errorf("want %v, got %v\n", script.ErrTimeout, err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("command was not killed, took %v\n", elapsed)
+	}
+}
+
+func TestExecRunner_context(t *testing.T) {
+	r := script.NewExecRunner(10 * time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := r.Run(ctx, script.Command{Name: "test", Line: "sleep 10 & sleep 10"})
+	if err != script.ErrTimeout {
+		t.Errorf("want %v, got %v\n", script.ErrTimeout, err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("command was not killed, took %v\n", elapsed)
+	}
+}
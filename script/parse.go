@@ -0,0 +1,211 @@
+package script
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/Bo0mer/yamt/metric"
+)
+
+// ParseNagios creates events from Nagios plugin output. A status event
+// carries the exit code and the state it maps to. Each performance data
+// item, e.g. 'time'=0.2s;1;2;0;10, becomes an event named after its label
+// and unit, with state derived from its warning and critical thresholds.
+func ParseNagios(name string, output []byte, exitCode int) []metric.Event {
+	status, perfdata := splitNagios(string(output))
+	attributes := map[string]string{"command": name}
+	if status != "" {
+		attributes["output"] = status
+	}
+
+	events := make([]metric.Event, 0)
+	events = append(events, metric.Event{
+		Name:       name + " status",
+		Value:      float64(exitCode),
+		State:      exitState(exitCode),
+		Attributes: attributes,
+	})
+	for _, item := range perfdata {
+		event, ok := parsePerfdata(item)
+		if !ok {
+			continue
+		}
+		event.Name = name + " " + event.Name
+		event.Attributes = map[string]string{"command": name}
+		events = append(events, event)
+	}
+	return events
+}
+
+// ParseLines creates events from lines in "name value [state]" format,
+// where state is one of ok, warning, critical or unknown. Empty lines are
+// skipped.
+func ParseLines(name string, output []byte) ([]metric.Event, error) {
+	events := make([]metric.Event, 0)
+	for i, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 2 || len(fields) > 3 {
+			return nil, fmt.Errorf("script: unsupported format on line %d: %q", i, line)
+		}
+		value, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("script: error parsing line %d: %v", i, err)
+		}
+		event := metric.Event{
+			Name:       name + " " + fields[0],
+			Value:      value,
+			Attributes: map[string]string{"command": name},
+		}
+		if len(fields) == 3 {
+			switch fields[2] {
+			case metric.StateOK, metric.StateWarning, metric.StateCritical, metric.StateUnknown:
+				event.State = fields[2]
+			default:
+				return nil, fmt.Errorf("script: unsupported format on line %d: %q", i, line)
+			}
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// exitState maps Nagios plugin exit codes to event states.
+func exitState(code int) string {
+	switch code {
+	case 0:
+		return metric.StateOK
+	case 1:
+		return metric.StateWarning
+	case 2:
+		return metric.StateCritical
+	}
+	return metric.StateUnknown
+}
+
+// splitNagios splits plugin output into the first line of text and the
+// performance data items. Performance data follows a pipe on the first
+// line, and in multi-line output everything after the first pipe in the
+// following lines.
+func splitNagios(output string) (string, []string) {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	status, perf := lines[0], ""
+	if pipe := strings.Index(status, "|"); pipe >= 0 {
+		status, perf = status[:pipe], status[pipe+1:]
+	}
+	inPerf := false
+	for _, line := range lines[1:] {
+		if inPerf {
+			perf += " " + line
+		} else if pipe := strings.Index(line, "|"); pipe >= 0 {
+			perf += " " + line[pipe+1:]
+			inPerf = true
+		}
+	}
+	return strings.TrimSpace(status), splitPerfdata(perf)
+}
+
+// splitPerfdata splits performance data on whitespace, honouring single
+// quoted labels which may contain spaces.
+func splitPerfdata(perf string) []string {
+	items := make([]string, 0)
+	var current bytes.Buffer
+	quoted := false
+	for _, r := range perf {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+			current.WriteRune(r)
+		case (r == ' ' || r == '\t') && !quoted:
+			if current.Len() > 0 {
+				items = append(items, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		items = append(items, current.String())
+	}
+	return items
+}
+
+// parsePerfdata parses a single 'label'=value[uom];[warn];[crit];[min];[max]
+// item. Items with unknown (U) or malformed values are skipped.
+func parsePerfdata(item string) (metric.Event, bool) {
+	eq := strings.LastIndex(item, "=")
+	if eq <= 0 {
+		return metric.Event{}, false
+	}
+	label := strings.Trim(item[:eq], "'")
+	parts := strings.Split(item[eq+1:], ";")
+
+	raw := parts[0]
+	end := len(raw)
+	for end > 0 && !strings.ContainsRune("0123456789.", rune(raw[end-1])) {
+		end--
+	}
+	uom := raw[end:]
+	value, err := strconv.ParseFloat(raw[:end], 64)
+	if err != nil {
+		return metric.Event{}, false
+	}
+
+	name := label
+	if uom != "" {
+		name += "(" + uom + ")"
+	}
+	state := metric.StateOK
+	if len(parts) > 1 && alerts(parts[1], value) {
+		state = metric.StateWarning
+	}
+	if len(parts) > 2 && alerts(parts[2], value) {
+		state = metric.StateCritical
+	}
+	return metric.Event{Name: name, Value: value, State: state}, true
+}
+
+// alerts tells whether value is outside the specified Nagios threshold
+// range, or inside it if the range starts with @. Ranges are in
+// [@][start:][end] format, start defaults to 0 and ~ means negative
+// infinity. Empty and malformed ranges never alert.
+func alerts(threshold string, value float64) bool {
+	if threshold == "" {
+		return false
+	}
+	inside := strings.HasPrefix(threshold, "@")
+	threshold = strings.TrimPrefix(threshold, "@")
+
+	start, end := 0.0, math.Inf(1)
+	var err error
+	if colon := strings.Index(threshold, ":"); colon >= 0 {
+		switch s := threshold[:colon]; s {
+		case "~":
+			start = math.Inf(-1)
+		case "":
+		default:
+			if start, err = strconv.ParseFloat(s, 64); err != nil {
+				return false
+			}
+		}
+		if e := threshold[colon+1:]; e != "" {
+			if end, err = strconv.ParseFloat(e, 64); err != nil {
+				return false
+			}
+		}
+	} else if end, err = strconv.ParseFloat(threshold, 64); err != nil {
+		return false
+	}
+
+	within := value >= start && value <= end
+	if inside {
+		return within
+	}
+	return !within
+}
@@ -0,0 +1,102 @@
+package script_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Bo0mer/yamt/metric"
+	"github.com/Bo0mer/yamt/script"
+)
+
+func TestParseNagios(t *testing.T) {
+	output := "DISK WARNING - free space: / 3326 MB (9%); | '/ used'=29584MB;29000;31000;0;32910 inodes=12%;;@0:10\n" +
+		"Long text with a = sign\n" +
+		"more text | time=0.2s;1;2 load=U;1;2\n" +
+		"count=7;5:;3:\n"
+
+	got := script.ParseNagios("disk", []byte(output), 1)
+	want := []metric.Event{
+		metric.Event{
+			Name:  "disk status",
+			Value: 1.0,
+			State: metric.StateWarning,
+			Attributes: map[string]string{
+				"command": "disk",
+				"output":  "DISK WARNING - free space: / 3326 MB (9%);",
+			},
+		},
+		metric.Event{Name: "disk / used(MB)", Value: 29584.0, State: metric.StateWarning, Attributes: map[string]string{"command": "disk"}},
+		metric.Event{Name: "disk inodes(%)", Value: 12.0, State: metric.StateOK, Attributes: map[string]string{"command": "disk"}},
+		metric.Event{Name: "disk time(s)", Value: 0.2, State: metric.StateOK, Attributes: map[string]string{"command": "disk"}},
+		metric.Event{Name: "disk count", Value: 7.0, State: metric.StateOK, Attributes: map[string]string{"command": "disk"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %#v, got %#v\n", want, got)
+	}
+}
+
+func TestParseNagios_thresholds(t *testing.T) {
+	tests := []struct {
+		perfdata string
+		state    string
+	}{
+		{"v=5;10;20", metric.StateOK},
+		{"v=15;10;20", metric.StateWarning},
+		{"v=25;10;20", metric.StateCritical},
+		{"v=-1;10;20", metric.StateCritical},
+		{"v=5;10:;5:", metric.StateWarning},
+		{"v=5;~:0;~:10", metric.StateWarning},
+		{"v=5;@0:10", metric.StateWarning},
+		{"v=11;@0:10", metric.StateOK},
+		{"v=11;bogus", metric.StateOK},
+	}
+	for _, test := range tests {
+		got := script.ParseNagios("c", []byte("OK | "+test.perfdata), 0)
+		if len(got) != 2 {
+			t.Errorf("%s: want 2 events, got %v\n", test.perfdata, got)
+			continue
+		}
+		if got[1].State != test.state {
+			t.Errorf("%s: want state %s, got %s\n", test.perfdata, test.state, got[1].State)
+		}
+	}
+}
+
+func TestParseNagios_exitCodes(t *testing.T) {
+	states := map[int]string{
+		0: metric.StateOK,
+		1: metric.StateWarning,
+		2: metric.StateCritical,
+		3: metric.StateUnknown,
+		4: metric.StateUnknown,
+	}
+	for code, state := range states {
+		got := script.ParseNagios("c", nil, code)
+		if got[0].State != state {
+			t.Errorf("exit code %d: want state %s, got %s\n", code, state, got[0].State)
+		}
+	}
+}
+
+func TestParseLines(t *testing.T) {
+	output := "queue.depth 42\n\nworkers 3 warning\n"
+	got, err := script.ParseLines("app", []byte(output))
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+
+	attributes := map[string]string{"command": "app"}
+	want := []metric.Event{
+		metric.Event{Name: "app queue.depth", Value: 42.0, Attributes: attributes},
+		metric.Event{Name: "app workers", Value: 3.0, State: metric.StateWarning, Attributes: attributes},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v\n", want, got)
+	}
+
+	for _, bad := range []string{"lonely", "name notanumber", "a 1 ok extra", "a 1 critcal"} {
+		if _, err := script.ParseLines("app", []byte(bad)); err == nil {
+			t.Errorf("%q: expected error, got nil\n", bad)
+		}
+	}
+}
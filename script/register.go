@@ -0,0 +1,47 @@
+package script
+
+import (
+	"fmt"
+
+	"github.com/Bo0mer/yamt/metric"
+	"github.com/Bo0mer/yamt/registry"
+)
+
+func init() {
+	registry.Register(registry.Collector{
+		Name:        "exec",
+		Description: "Report output of commands given by -exec",
+		Options: []registry.Option{
+			{Name: "exec", Kind: registry.Repeated, Usage: "Command to run on each interval, in name=format:command format where format is nagios or lines"},
+			{Name: "exec-timeout", Kind: registry.Duration, Usage: "Time after which commands are killed, at the latest just before the collection timeout", Default: "10s"},
+			{Name: "exec-concurrency", Kind: registry.Int, Usage: "Maximum number of commands running at the same time", Default: "4"},
+		},
+		EnabledBy: "exec",
+		New:       newCommandCollector,
+	})
+}
+
+func newCommandCollector(c registry.Config) ([]metric.Collector, error) {
+	specs := c.Strings("exec")
+	commands := make([]Command, 0, len(specs))
+	for _, spec := range specs {
+		cmd, err := ParseCommand(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -exec: %v", err)
+		}
+		commands = append(commands, cmd)
+	}
+	timeout, err := c.Duration("exec-timeout")
+	if err != nil {
+		return nil, err
+	}
+	concurrency, err := c.Int("exec-concurrency")
+	if err != nil {
+		return nil, err
+	}
+	cc, err := NewCommandCollector(NewExecRunner(timeout), commands, concurrency)
+	if err != nil {
+		return nil, err
+	}
+	return []metric.Collector{metric.Named("exec", cc)}, nil
+}
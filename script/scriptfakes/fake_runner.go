@@ -0,0 +1,81 @@
+// This file was generated by counterfeiter
+package scriptfakes
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Bo0mer/yamt/script"
+)
+
+type FakeRunner struct {
+	RunStub        func(ctx context.Context, cmd script.Command) (script.Result, error)
+	runMutex       sync.RWMutex
+	runArgsForCall []struct {
+		ctx context.Context
+		cmd script.Command
+	}
+	runReturns struct {
+		result1 script.Result
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeRunner) Run(ctx context.Context, cmd script.Command) (script.Result, error) {
+	fake.runMutex.Lock()
+	fake.runArgsForCall = append(fake.runArgsForCall, struct {
+		ctx context.Context
+		cmd script.Command
+	}{ctx, cmd})
+	fake.recordInvocation("Run", []interface{}{ctx, cmd})
+	fake.runMutex.Unlock()
+	if fake.RunStub != nil {
+		return fake.RunStub(ctx, cmd)
+	} else {
+		return fake.runReturns.result1, fake.runReturns.result2
+	}
+}
+
+func (fake *FakeRunner) RunCallCount() int {
+	fake.runMutex.RLock()
+	defer fake.runMutex.RUnlock()
+	return len(fake.runArgsForCall)
+}
+
+func (fake *FakeRunner) RunArgsForCall(i int) (context.Context, script.Command) {
+	fake.runMutex.RLock()
+	defer fake.runMutex.RUnlock()
+	return fake.runArgsForCall[i].ctx, fake.runArgsForCall[i].cmd
+}
+
+func (fake *FakeRunner) RunReturns(result1 script.Result, result2 error) {
+	fake.RunStub = nil
+	fake.runReturns = struct {
+		result1 script.Result
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRunner) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.runMutex.RLock()
+	defer fake.runMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeRunner) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ script.Runner = new(FakeRunner)
@@ -0,0 +1,80 @@
+package sysstat
+
+import (
+	"fmt"
+
+	"github.com/Bo0mer/yamt/metric"
+)
+
+// SystemCollector reports usage of system wide kernel resources.
+type SystemCollector struct {
+	reader SystemStatReader
+}
+
+// NewSystemCollector returns brand new system resource collector.
+func NewSystemCollector(reader SystemStatReader) (*SystemCollector, error) {
+	c := &SystemCollector{
+		reader: reader,
+	}
+	if _, err := c.getState(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Collect reads resource usage and creates events for it. Resources with a
+// limit are reported in percents of the limit as well.
+func (c *SystemCollector) Collect() ([]metric.Event, error) {
+	stat, err := c.getState()
+	if err != nil {
+		return nil, err
+	}
+
+	files := stat.FilesAllocated - stat.FilesUnused
+	if stat.FilesUnused > stat.FilesAllocated {
+		files = 0
+	}
+
+	events := make([]metric.Event, 0)
+	events = append(events, event("files", float64(files)))
+	events = append(events, event("files max", float64(stat.FilesMax)))
+	events = append(events, event("files usage(%)", percent(files, stat.FilesMax)))
+
+	events = append(events, event("threads", float64(stat.Threads)))
+	events = append(events, event("pid max", float64(stat.PIDMax)))
+	events = append(events, event("pids usage(%)", percent(stat.Threads, stat.PIDMax)))
+
+	events = append(events, event("entropy available(bits)", float64(stat.EntropyAvail)))
+
+	events = append(events, event("inodes", float64(stat.Inodes)))
+	events = append(events, event("inodes free", float64(stat.InodesFree)))
+
+	events = append(events, event("uptime(s)", stat.Uptime))
+	events = append(events, event("idle(s)", stat.Idle))
+
+	return events, nil
+}
+
+// getState reads current resource usage.
+func (c *SystemCollector) getState() (SystemStat, error) {
+	stat, err := c.reader.ReadStats()
+	if err != nil {
+		return SystemStat{}, fmt.Errorf("collector: error reading stats: %v", err)
+	}
+	return stat, nil
+}
+
+// percent returns used in percents of max, or zero if max is zero.
+func percent(used, max uint64) float64 {
+	if max == 0 {
+		return 0
+	}
+	return float64(used) / float64(max) * 100
+}
+
+func event(name string, value float64) metric.Event {
+	return metric.Event{
+		Name:  "system " + name,
+		Value: value,
+	}
+}
@@ -0,0 +1,65 @@
+package sysstat_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Bo0mer/yamt/metric"
+	"github.com/Bo0mer/yamt/sysstat"
+	"github.com/Bo0mer/yamt/sysstat/sysstatfakes"
+)
+
+// Test that *SystemCollector implements metric.Collector
+var _ metric.Collector = (*sysstat.SystemCollector)(nil)
+
+func TestNewSystemCollector(t *testing.T) {
+	errReader := new(sysstatfakes.FakeSystemStatReader)
+	errReader.ReadStatsReturns(sysstat.SystemStat{}, errors.New("kaboom"))
+	_, err := sysstat.NewSystemCollector(errReader)
+	if err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+func TestSystemCollectorCollect(t *testing.T) {
+	reader := new(sysstatfakes.FakeSystemStatReader)
+	reader.ReadStatsReturns(sysstat.SystemStat{
+		FilesAllocated: 300,
+		FilesUnused:    100,
+		FilesMax:       1000,
+		Threads:        512,
+		PIDMax:         1024,
+		EntropyAvail:   256,
+		Inodes:         2000,
+		InodesFree:     500,
+		Uptime:         60.5,
+		Idle:           100.25,
+	}, nil)
+
+	want := []metric.Event{
+		metric.Event{Name: "system files", Value: 200.0},
+		metric.Event{Name: "system files max", Value: 1000.0},
+		metric.Event{Name: "system files usage(%)", Value: 20.0},
+		metric.Event{Name: "system threads", Value: 512.0},
+		metric.Event{Name: "system pid max", Value: 1024.0},
+		metric.Event{Name: "system pids usage(%)", Value: 50.0},
+		metric.Event{Name: "system entropy available(bits)", Value: 256.0},
+		metric.Event{Name: "system inodes", Value: 2000.0},
+		metric.Event{Name: "system inodes free", Value: 500.0},
+		metric.Event{Name: "system uptime(s)", Value: 60.5},
+		metric.Event{Name: "system idle(s)", Value: 100.25},
+	}
+
+	c, err := sysstat.NewSystemCollector(reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+	got, err := c.Collect()
+	if err != nil {
+		t.Errorf("unexpected error: %v\n", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v\n", want, got)
+	}
+}
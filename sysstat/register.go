@@ -0,0 +1,20 @@
+package sysstat
+
+import (
+	"github.com/Bo0mer/yamt/metric"
+	"github.com/Bo0mer/yamt/registry"
+)
+
+func init() {
+	registry.Register(registry.Collector{
+		Name:        "system",
+		Description: "Report kernel resource limits and uptime",
+		New: func(c registry.Config) ([]metric.Collector, error) {
+			sc, err := NewSystemCollector(DefaultProcReader)
+			if err != nil {
+				return nil, err
+			}
+			return []metric.Collector{metric.Named("system", sc)}, nil
+		},
+	})
+}
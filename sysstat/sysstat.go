@@ -0,0 +1,133 @@
+package sysstat
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/Bo0mer/yamt/internal"
+	"github.com/Bo0mer/yamt/internal/hostfs"
+)
+
+//go:generate counterfeiter . SystemStatReader
+
+// SystemStat represents usage of system wide kernel resources.
+type SystemStat struct {
+	// Number of allocated file handles.
+	FilesAllocated uint64
+	// Number of allocated but unused file handles.
+	FilesUnused uint64
+	// Maximum number of file handles.
+	FilesMax uint64
+
+	// Number of threads, i.e. kernel scheduling entities.
+	Threads uint64
+	// Maximum process ID, which limits the number of threads.
+	PIDMax uint64
+
+	// Available entropy, in bits.
+	EntropyAvail uint64
+
+	// Number of allocated inodes.
+	Inodes uint64
+	// Number of free inodes.
+	InodesFree uint64
+
+	// Time since boot, in seconds.
+	Uptime float64
+	// Time spent idle since boot, summed across all CPUs, in seconds.
+	Idle float64
+}
+
+// SystemStatReader should read usage of system wide kernel resources.
+type SystemStatReader interface {
+	ReadStats() (SystemStat, error)
+}
+
+// ProcReader reads usage of system wide kernel resources from procfs.
+type ProcReader struct {
+	dir string
+}
+
+// NewProcReader creates ProcReader that reads from the specified procfs
+// directory. Paths under /proc are resolved relative to hostfs.ProcRoot.
+func NewProcReader(dir string) *ProcReader {
+	return &ProcReader{
+		dir: dir,
+	}
+}
+
+// DefaultProcReader is the default implementation of SystemStatReader.
+// It reads from /proc.
+var DefaultProcReader SystemStatReader = NewProcReader("/proc")
+
+// ReadSystemStats is shorthand for DefaultProcReader.ReadStats.
+func ReadSystemStats() (SystemStat, error) {
+	return DefaultProcReader.ReadStats()
+}
+
+// ReadStats reads usage of system wide kernel resources.
+func (r *ProcReader) ReadStats() (SystemStat, error) {
+	dir := hostfs.Resolve(r.dir)
+	stat := SystemStat{}
+
+	fields, err := readFields(dir, "sys/fs/file-nr", 3)
+	if err != nil {
+		return SystemStat{}, err
+	}
+	p := &internal.ErrParser{}
+	stat.FilesAllocated = p.ParseUint64(fields[0])
+	stat.FilesUnused = p.ParseUint64(fields[1])
+	stat.FilesMax = p.ParseUint64(fields[2])
+
+	if fields, err = readFields(dir, "loadavg", 4); err != nil {
+		return SystemStat{}, err
+	}
+	// The fourth field is in runnable/total format.
+	if slash := strings.Index(fields[3], "/"); slash >= 0 {
+		stat.Threads = p.ParseUint64(fields[3][slash+1:])
+	}
+
+	if fields, err = readFields(dir, "sys/kernel/pid_max", 1); err != nil {
+		return SystemStat{}, err
+	}
+	stat.PIDMax = p.ParseUint64(fields[0])
+
+	if fields, err = readFields(dir, "sys/kernel/random/entropy_avail", 1); err != nil {
+		return SystemStat{}, err
+	}
+	stat.EntropyAvail = p.ParseUint64(fields[0])
+
+	if fields, err = readFields(dir, "sys/fs/inode-nr", 2); err != nil {
+		return SystemStat{}, err
+	}
+	stat.Inodes = p.ParseUint64(fields[0])
+	stat.InodesFree = p.ParseUint64(fields[1])
+
+	if fields, err = readFields(dir, "uptime", 2); err != nil {
+		return SystemStat{}, err
+	}
+	stat.Uptime = p.ParseFloat64(fields[0])
+	stat.Idle = p.ParseFloat64(fields[1])
+
+	if err := p.Err(); err != nil {
+		return SystemStat{}, fmt.Errorf("readsysstat: error parsing stats: %v", err)
+	}
+	return stat, nil
+}
+
+// readFields reads the file at name relative to dir and returns its
+// whitespace separated fields, at least n of them.
+func readFields(dir, name string, n int) ([]string, error) {
+	path := filepath.Join(dir, name)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("readsysstat: error reading from %s: %v", path, err)
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) < n {
+		return nil, fmt.Errorf("readsysstat: unsupported format of %s: %q", path, data)
+	}
+	return fields, nil
+}
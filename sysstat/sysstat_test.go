@@ -0,0 +1,38 @@
+package sysstat_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Bo0mer/yamt/sysstat"
+)
+
+func TestProcReader(t *testing.T) {
+	r := sysstat.NewProcReader("testdata/proc")
+	got, err := r.ReadStats()
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n", err)
+	}
+
+	want := sysstat.SystemStat{
+		FilesAllocated: 10432,
+		FilesMax:       1048576,
+		Threads:        1048,
+		PIDMax:         4194304,
+		EntropyAvail:   3764,
+		Inodes:         281934,
+		InodesFree:     12011,
+		Uptime:         350735.47,
+		Idle:           1234567.89,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %#v, got %#v\n", want, got)
+	}
+}
+
+func TestProcReader_missing(t *testing.T) {
+	r := sysstat.NewProcReader("testdata/missing")
+	if _, err := r.ReadStats(); err == nil {
+		t.Error("expected error, got nil")
+	}
+}